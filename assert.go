@@ -5,11 +5,14 @@ import (
 	"reflect"
 	"regexp"
 	"runtime"
+	"strings"
+	"time"
 )
 
 // TestingTB is an interface mimicking testing.TB (except for Skip*) interface which prevents users to implement itself.
 // See testing.TB for details.
 type TestingTB interface {
+	Cleanup(f func())
 	Error(args ...interface{})
 	Errorf(format string, args ...interface{})
 	Fail()
@@ -31,10 +34,21 @@ type HookedTestingTB struct {
 	// Helpers is the slice of the registered helper functions.
 	// Helper functions are identified by string "file:line".
 	Helpers []string
+	// Subtests is the slice of the subtests run by method Run.
+	Subtests []*HookedTestingTB
+	// Duration is the elapsed time of the test, which is measured by method Run for the subtests.
+	Duration time.Duration
+	// Color indicates whether the failure messages are colored or not, which is inherited by the subtests.
+	// The failure messages are not colored by default regardless of the environment.
+	Color bool
 	// name is the name of TestingTB for method Name.
 	name string
 	// failed indicates whether the current test has failed already or not.
 	failed bool
+	// skipped indicates whether the current test has been skipped or not.
+	skipped bool
+	// cleanups is the slice of the registered cleanup functions.
+	cleanups []func()
 }
 
 // NewHookedTestingTB returns a new HookedTestingTB.
@@ -42,10 +56,27 @@ func NewHookedTestingTB(name string) *HookedTestingTB {
 	return &HookedTestingTB{
 		Messages: []string{},
 		Helpers:  []string{},
+		Subtests: []*HookedTestingTB{},
 		name:     name,
 	}
 }
 
+// Cleanup is for interface TestingTB.
+// The registered functions are called by DoCleanups.
+func (tb *HookedTestingTB) Cleanup(f func()) {
+	tb.cleanups = append(tb.cleanups, f)
+}
+
+// DoCleanups calls the registered cleanup functions in last added, first called order.
+// The called functions are unregistered.
+func (tb *HookedTestingTB) DoCleanups() {
+	for len(tb.cleanups) > 0 {
+		f := tb.cleanups[len(tb.cleanups)-1]
+		tb.cleanups = tb.cleanups[:len(tb.cleanups)-1]
+		f()
+	}
+}
+
 // Error is for interface TestingTB.
 func (tb *HookedTestingTB) Error(args ...interface{}) {
 	tb.Log(append([]interface{}{"ERROR: "}, args...)...)
@@ -107,10 +138,61 @@ func (tb *HookedTestingTB) Name() string {
 	return tb.name
 }
 
+// Run runs the function as the subtest named "tb.Name()/name", and returns true if the subtest has not failed.
+// The subtest is appended to Subtests, and its cleanup functions are called at the end of it.
+// The elapsed time of the subtest including its cleanups is recorded in its Duration.
+// The failure of the subtest marks tb failed, but FailNow and SkipNow in the subtest do not stop tb.
+func (tb *HookedTestingTB) Run(name string, f func(tb *HookedTestingTB)) bool {
+	sub := NewHookedTestingTB(tb.name + "/" + name)
+	sub.Color = tb.Color
+	tb.Subtests = append(tb.Subtests, sub)
+	start := time.Now()
+	func() {
+		defer sub.DoCleanups()
+		defer func() {
+			if v := recover(); v != nil {
+				if v != fmt.Sprintf("HookedTestingTB(%q): FAIL NOW", sub.name) && v != fmt.Sprintf("HookedTestingTB(%q): SKIP NOW", sub.name) {
+					panic(v)
+				}
+			}
+		}()
+		f(sub)
+	}()
+	sub.Duration = time.Since(start)
+	if sub.Failed() {
+		tb.Fail()
+	}
+	return !sub.Failed()
+}
+
+// Skip is equivalent to Log followed by SkipNow.
+func (tb *HookedTestingTB) Skip(args ...interface{}) {
+	tb.Log(append([]interface{}{"SKIP: "}, args...)...)
+	tb.SkipNow()
+}
+
+// Skipf is equivalent to Logf followed by SkipNow.
+func (tb *HookedTestingTB) Skipf(format string, args ...interface{}) {
+	tb.Logf("SKIP: "+format, args...)
+	tb.SkipNow()
+}
+
+// SkipNow marks the current test skipped, and stops its execution.
+func (tb *HookedTestingTB) SkipNow() {
+	tb.skipped = true
+	panic(fmt.Sprintf("HookedTestingTB(%q): SKIP NOW", tb.name))
+}
+
+// Skipped returns true if the current test has been skipped.
+func (tb *HookedTestingTB) Skipped() bool {
+	return tb.skipped
+}
+
 // Assert is an assertion wrapper.
 type Assert struct {
 	tb       TestingTB
 	expected []interface{}
+	options  []CompareOption
 }
 
 // New returns a new Assert with the testing context and the expected values.
@@ -121,19 +203,89 @@ func New(tb TestingTB, expected ...interface{}) *Assert {
 	}
 }
 
+// New returns a new Assert with the same testing context and comparison options, and the given expected values.
+func (assert *Assert) New(expected ...interface{}) *Assert {
+	return New(assert.tb, expected...).WithOptions(assert.options...)
+}
+
+// WithOptions returns a new Assert with the same testing context and expected values, and the comparison options added.
+// The options are used by Equal, ExpectPanic and ReceivesEqual.
+func (assert *Assert) WithOptions(options ...CompareOption) *Assert {
+	return &Assert{
+		tb:       assert.tb,
+		expected: assert.expected,
+		options:  append(append([]CompareOption{}, assert.options...), options...),
+	}
+}
+
+// equal returns true if the expected value deeply equals the actual value under the comparison options.
+// If the values differ, the notes explaining the mismatch are returned, e.g. the different types of the same-looking values.
+func (assert *Assert) equal(expected, actual interface{}) (bool, []string) {
+	c := newComparer(assert.options)
+	if c.equal(reflect.ValueOf(expected), reflect.ValueOf(actual)) {
+		return true, nil
+	}
+	return false, c.notes()
+}
+
+// isErrorLike returns true if the value is nil or an error, which looks like the error returned by the function.
+func isErrorLike(v interface{}) bool {
+	_, ok := v.(error)
+	return v == nil || ok
+}
+
+// explainCount returns the explanation of the mismatched numbers of the expected and actual values.
+// The values are listed side by side with the likely cause of the mismatch.
+// If withoutError is true, the actual values are the ones passed to EqualWithoutError except for the last error.
+func explainCount(expected, actual []interface{}, withoutError bool) string {
+	lines := []string{fmt.Sprintf("expected %d value(s), but got %d value(s):", len(expected), len(actual))}
+	indent := func(s string) string {
+		return strings.ReplaceAll(s, "\n", "\n    ")
+	}
+	for i := 0; i < len(expected) || i < len(actual); i++ {
+		expectedStr, actualStr := "nothing", "nothing"
+		if i < len(expected) {
+			expectedStr = indent(formatValue(expected[i]))
+		}
+		if i < len(actual) {
+			actualStr = indent(formatValue(actual[i]))
+		}
+		lines = append(lines, fmt.Sprintf("  #%d: expected %s, got %s", i, expectedStr, actualStr))
+	}
+	switch {
+	case !withoutError && len(actual) == len(expected)+1 && isErrorLike(actual[len(actual)-1]):
+		lines = append(lines, "hint: the last actual value looks like the returned error; use EqualWithoutError to check that it is nil")
+	case withoutError && len(expected) == len(actual)+1 && isErrorLike(expected[len(expected)-1]):
+		lines = append(lines, "hint: the last expected value looks like the error, but EqualWithoutError excludes the error from the compared values")
+	case len(expected) > 1 && len(actual) == 1 && reflect.ValueOf(actual[0]).Kind() == reflect.Slice && reflect.ValueOf(actual[0]).Len() == len(expected):
+		lines = append(lines, "hint: the actual value is the slice of the expected number of elements; pass its elements with ... or expect the slice itself")
+	}
+	return strings.Join(lines, "\n")
+}
+
 // Equal checks that the given actual values equals the expected values.
+// The values are compared as reflect.DeepEqual does, but the cyclic values must have the same shapes.
+// The unexported fields are compared unless ignored by IgnoreUnexported, and the mismatch in them is noted unless allowed by AllowUnexported.
+// The mismatch caused by the types is also noted, e.g. 1 (int) and 1 (int64), or the nil pointer in the interface and the nil interface.
 func (assert *Assert) Equal(actual ...interface{}) {
 	assert.tb.Helper()
 	if len(assert.expected) != len(actual) {
-		assert.tb.Fatalf("expected %d value(s), but got %d value(s)", len(assert.expected), len(actual))
+		assert.tb.Fatalf("%s", explainCount(assert.expected, actual, false))
 	} else {
 		str := ""
 		for i, expected := range assert.expected {
-			if !reflect.DeepEqual(expected, actual[i]) {
+			if equal, notes := assert.equal(expected, actual[i]); !equal {
 				if str != "" {
 					str += "\n"
 				}
-				str += fmt.Sprintf("at #%d value, expected %#v (%T), but got %#v (%T)", i, expected, expected, actual[i], actual[i])
+				expectedStr, actualStr := formatValue(expected), formatValue(actual[i])
+				str += fmt.Sprintf("at #%d value, expected %s, but got %s", i, expectedStr, actualStr)
+				if strings.Contains(expectedStr, "\n") || strings.Contains(actualStr, "\n") {
+					str += "\ndiff (-expected +actual):\n" + diffLines(expectedStr, actualStr, colorEnabled(assert.tb))
+				}
+				for _, note := range notes {
+					str += "\n" + note
+				}
 			}
 		}
 		if str != "" {
@@ -152,6 +304,10 @@ func (assert *Assert) EqualWithoutError(actualErr ...interface{}) {
 	if err != nil {
 		assert.tb.Fatalf("unexpected error: %s", err)
 	}
+	if len(assert.expected) != len(actualErr)-1 {
+		assert.tb.Fatalf("%s", explainCount(assert.expected, actualErr[0:len(actualErr)-1], true))
+		return
+	}
 	assert.Equal(actualErr[0 : len(actualErr)-1]...)
 }
 
@@ -175,21 +331,30 @@ func (assert *Assert) ExpectError(_err ...interface{}) {
 			assert.tb.Fatalf("the number of error pattern must be at most one")
 			return
 		}
-		pattern, ok := assert.expected[0].(string)
-		if !ok {
-			assert.tb.Fatalf("error pattern must be string")
-			return
-		}
-		if matched, e := regexp.MatchString(pattern, err.Error()); e != nil {
-			assert.tb.Fatalf("malformed expected error pattern: %s", e)
+		if matched, e := matchPattern("error", assert.expected[0], err.Error()); e != nil {
+			assert.tb.Fatalf("%s", e)
 			return
 		} else if !matched {
-			assert.tb.Fatalf("expected error pattern %q, but got error %q", pattern, err)
+			assert.tb.Fatalf("expected error pattern %q, but got error %q", assert.expected[0], err)
 			return
 		}
 	}
 }
 
+// matchPattern returns whether the string matches the pattern of the kind or not.
+// matchPattern returns the error if the pattern is not a valid regular expression string.
+func matchPattern(kind string, pattern interface{}, s string) (bool, error) {
+	str, ok := pattern.(string)
+	if !ok {
+		return false, fmt.Errorf("%s pattern must be string", kind)
+	}
+	matched, err := regexp.MatchString(str, s)
+	if err != nil {
+		return false, fmt.Errorf("malformed expected %s pattern: %s", kind, err)
+	}
+	return matched, nil
+}
+
 // ExpectPanic checks that panic is called at least once.
 // The expected values must be one expected object passed to panic.
 func (assert *Assert) ExpectPanic(callback func()) {
@@ -204,8 +369,8 @@ func (assert *Assert) ExpectPanic(callback func()) {
 	if len(assert.expected) != 1 {
 		assert.tb.Fatalf("the number of the expected objects must be one")
 	}
-	if !reflect.DeepEqual(assert.expected[0], v) {
-		assert.tb.Errorf("expected %#v (%T), but got %#v (%T)", assert.expected[0], assert.expected[0], v, v)
+	if equal, _ := assert.equal(assert.expected[0], v); !equal {
+		assert.tb.Errorf("expected %s, but got %s", formatValue(assert.expected[0]), formatValue(v))
 	}
 }
 
@@ -105,6 +105,105 @@ func TestHookedTestingTBFatalfPreventsExecution(t *testing.T) {
 	}
 }
 
+func TestHookedTestingTBCleanup(t *testing.T) {
+	// Cleanup functions should be called in last added, first called order by DoCleanups
+	tb := NewHookedTestingTB("test")
+	order := []int{}
+	tb.Cleanup(func() {
+		order = append(order, 1)
+	})
+	tb.Cleanup(func() {
+		order = append(order, 2)
+		tb.Cleanup(func() {
+			order = append(order, 3)
+		})
+	})
+	tb.DoCleanups()
+	tb.DoCleanups()
+	if !reflect.DeepEqual(order, []int{2, 3, 1}) {
+		t.Fatalf("test: unexpected order of cleanups: %#v", order)
+	}
+}
+
+func TestHookedTestingTBRun(t *testing.T) {
+	// Subtests should be recorded, and their failure should mark the parent failed
+	tb := NewHookedTestingTB("test")
+	cleaned := false
+	if !tb.Run("ok", func(sub *HookedTestingTB) {
+		sub.Cleanup(func() {
+			cleaned = true
+		})
+		sub.Log("hello")
+	}) {
+		t.Fatalf("test: unexpected Run(\"ok\") == false")
+	}
+	if !cleaned {
+		t.Fatalf("test: unexpected uncalled cleanup")
+	}
+	if tb.Failed() {
+		t.Fatalf("test: unexpected Failed() == true")
+	}
+	if tb.Run("skip", func(sub *HookedTestingTB) {
+		sub.Skipf("%s!", "skipped")
+		sub.Log("unreachable")
+	}) != true {
+		t.Fatalf("test: unexpected Run(\"skip\") == false")
+	}
+	if tb.Run("fatal", func(sub *HookedTestingTB) {
+		sub.Run("nested", func(sub *HookedTestingTB) {
+			sub.Fatal("hell")
+		})
+		sub.Log("reachable")
+	}) != false {
+		t.Fatalf("test: unexpected Run(\"fatal\") == true")
+	}
+	if !tb.Failed() {
+		t.Fatalf("test: unexpected Failed() == false")
+	}
+	if len(tb.Subtests) != 3 {
+		t.Fatalf("test: unexpected Subtests: %#v", tb.Subtests)
+	}
+	for i, expected := range []struct {
+		name     string
+		messages []string
+		failed   bool
+		skipped  bool
+	}{
+		{"test/ok", []string{"hello"}, false, false},
+		{"test/skip", []string{"SKIP: skipped!"}, false, true},
+		{"test/fatal", []string{"reachable"}, true, false},
+	} {
+		sub := tb.Subtests[i]
+		if !(sub.Name() == expected.name && reflect.DeepEqual(sub.Messages, expected.messages) && sub.Failed() == expected.failed && sub.Skipped() == expected.skipped) {
+			t.Fatalf("test: unexpected Subtests[%d]: %#v", i, sub)
+		}
+	}
+	if nested := tb.Subtests[2].Subtests; !(len(nested) == 1 && nested[0].Name() == "test/fatal/nested" && reflect.DeepEqual(nested[0].Messages, []string{"FATAL: hell"})) {
+		t.Fatalf("test: unexpected nested Subtests: %#v", nested)
+	}
+	var panicObj interface{}
+	func() {
+		defer func() {
+			panicObj = recover()
+		}()
+		tb.Run("panic", func(sub *HookedTestingTB) {
+			panic("hello")
+		})
+	}()
+	if panicObj != "hello" {
+		t.Fatalf("test: unexpected panic object: %#v", panicObj)
+	}
+}
+
+func TestAssertNew(t *testing.T) {
+	// Derived Assert should share the testing context
+	tb := NewHookedTestingTB("test")
+	assert := New(tb, "hello").New("world")
+	if !(assert.tb == tb && reflect.DeepEqual(assert.expected, []interface{}{"world"})) {
+		t.Fatalf("test: unexpected Assert: %#v", assert)
+	}
+}
+
 func TestAssertEqual(t *testing.T) {
 	// test1: Test helper registration
 	tb1 := NewHookedTestingTB("test1")
@@ -143,7 +242,7 @@ func TestAssertEqual(t *testing.T) {
 	if !reflect.DeepEqual(tb3.Messages, []string{
 		"ERROR: at #0 value, expected \"hello\" (string), but got \"hell\" (string)",
 		"ERROR: at #0 value, expected \"hello\" (string), but got \"hell\" (string)\nat #1 value, expected \"world\" (string), but got \"w0rld\" (string)",
-		"FATAL: expected 0 value(s), but got 1 value(s)",
+		"FATAL: expected 0 value(s), but got 1 value(s):\n  #0: expected nothing, got \"hello\" (string)",
 	}) {
 		t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
 	}
@@ -206,6 +305,52 @@ func TestAssertEqualWithoutError(t *testing.T) {
 	}
 }
 
+func TestAssertEqualCountMismatch(t *testing.T) {
+	tb := NewHookedTestingTB("test")
+	for _, f := range []func(){
+		func() {
+			New(tb, "hello").Equal(func() (string, error) {
+				return "hello", nil
+			}())
+		},
+		func() {
+			New(tb, "hello", error(nil)).EqualWithoutError("hello", error(nil))
+		},
+		func() {
+			New(tb, 1, 2).Equal([]int{1, 2})
+		},
+		func() {
+			New(tb, 1, 2).Equal(1)
+		},
+	} {
+		func() {
+			defer func() {
+				recover()
+			}()
+			f()
+		}()
+	}
+	if !reflect.DeepEqual(tb.Messages, []string{
+		"FATAL: expected 1 value(s), but got 2 value(s):\n" +
+			"  #0: expected \"hello\" (string), got \"hello\" (string)\n" +
+			"  #1: expected nothing, got nil (<nil>)\n" +
+			"hint: the last actual value looks like the returned error; use EqualWithoutError to check that it is nil",
+		"FATAL: expected 2 value(s), but got 1 value(s):\n" +
+			"  #0: expected \"hello\" (string), got \"hello\" (string)\n" +
+			"  #1: expected nil (<nil>), got nothing\n" +
+			"hint: the last expected value looks like the error, but EqualWithoutError excludes the error from the compared values",
+		"FATAL: expected 2 value(s), but got 1 value(s):\n" +
+			"  #0: expected 1 (int), got []int{1, 2} ([]int)\n" +
+			"  #1: expected 2 (int), got nothing\n" +
+			"hint: the actual value is the slice of the expected number of elements; pass its elements with ... or expect the slice itself",
+		"FATAL: expected 2 value(s), but got 1 value(s):\n" +
+			"  #0: expected 1 (int), got 1 (int)\n" +
+			"  #1: expected 2 (int), got nothing",
+	}) {
+		t.Fatalf("test: unexpected Messages: %#v", tb.Messages)
+	}
+}
+
 func TestAssertExpectError(t *testing.T) {
 	// test1: Test helper registration
 	tb1 := NewHookedTestingTB("test1")
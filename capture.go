@@ -0,0 +1,89 @@
+package goassert
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"os"
+	"sync"
+)
+
+// Capture calls the callback with redirecting os.Stdout, os.Stderr and the output of the standard logger, and returns the captured outputs.
+// On Unix, the file descriptors 1 and 2 are also redirected, so the outputs written directly to them, e.g. by cgo, are captured.
+// The output of the standard logger is captured as stderr.
+// The redirections are restored even if the callback calls FailNow or panics.
+// Capture must not be called in parallel, because it replaces the global variables.
+func Capture(tb TestingTB, callback func()) (stdout, stderr string) {
+	tb.Helper()
+	var files [2][2]*os.File
+	for i := range files {
+		r, w, err := os.Pipe()
+		if err != nil {
+			for _, pipe := range files[:i] {
+				pipe[0].Close()
+				pipe[1].Close()
+			}
+			tb.Fatalf("cannot create pipe: %s", err)
+			return "", ""
+		}
+		files[i] = [2]*os.File{r, w}
+	}
+	var buffers [2]bytes.Buffer
+	var wg sync.WaitGroup
+	for i := range files {
+		wg.Add(1)
+		go func(r *os.File, buffer *bytes.Buffer) {
+			defer wg.Done()
+			io.Copy(buffer, r)
+			r.Close()
+		}(files[i][0], &buffers[i])
+	}
+	var restores [2]func()
+	for i := range files {
+		restore, err := redirectFD(i+1, files[i][1])
+		if err != nil {
+			for _, restore := range restores[:i] {
+				restore()
+			}
+			for _, pipe := range files {
+				pipe[1].Close()
+			}
+			wg.Wait()
+			tb.Fatalf("cannot redirect file descriptor %d: %s", i+1, err)
+			return "", ""
+		}
+		restores[i] = restore
+	}
+	origStdout, origStderr, origLog := os.Stdout, os.Stderr, log.Writer()
+	os.Stdout, os.Stderr = files[0][1], files[1][1]
+	log.SetOutput(files[1][1])
+	defer func() {
+		os.Stdout, os.Stderr = origStdout, origStderr
+		log.SetOutput(origLog)
+		for _, restore := range restores {
+			restore()
+		}
+		for _, pipe := range files {
+			pipe[1].Close()
+		}
+		wg.Wait()
+		stdout, stderr = buffers[0].String(), buffers[1].String()
+	}()
+	callback()
+	return
+}
+
+// OutputMatches checks that the output matches the expected pattern.
+// The expected values must be one output pattern string.
+func (assert *Assert) OutputMatches(output string) {
+	assert.tb.Helper()
+	if len(assert.expected) != 1 {
+		assert.tb.Fatalf("the number of output pattern must be one")
+		return
+	}
+	if matched, err := matchPattern("output", assert.expected[0], output); err != nil {
+		assert.tb.Fatalf("%s", err)
+	} else if !matched {
+		assert.tb.Errorf("expected output pattern %q, but got output %q", assert.expected[0], output)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build unix && !linux
+
+package goassert
+
+import "syscall"
+
+// dup2 duplicates the file descriptor oldfd onto newfd.
+func dup2(oldfd, newfd int) error {
+	return syscall.Dup2(oldfd, newfd)
+}
@@ -0,0 +1,9 @@
+package goassert
+
+import "syscall"
+
+// dup2 duplicates the file descriptor oldfd onto newfd.
+// syscall.Dup2 is missing on some architectures of Linux such as arm64, so Dup3 is used instead.
+func dup2(oldfd, newfd int) error {
+	return syscall.Dup3(oldfd, newfd, 0)
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package goassert
+
+import "os"
+
+// redirectFD does nothing on the platforms without dup2, so only os.Stdout and os.Stderr are redirected.
+func redirectFD(fd int, file *os.File) (func(), error) {
+	return func() {}, nil
+}
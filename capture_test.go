@@ -0,0 +1,90 @@
+package goassert
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestCapture(t *testing.T) {
+	defer func(flags int) {
+		log.SetFlags(flags)
+	}(log.Flags())
+	log.SetFlags(0)
+	stdout, stderr, logWriter := os.Stdout, os.Stderr, log.Writer()
+	// test1: only normal uses
+	tb1 := NewHookedTestingTB("test1")
+	capturedStdout, capturedStderr := Capture(tb1, func() {
+		fmt.Println("hello")
+		fmt.Fprintln(os.Stderr, "world")
+		log.Print("logged")
+		fmt.Print("!")
+	})
+	if !(capturedStdout == "hello\n!" && capturedStderr == "world\nlogged\n") {
+		t.Fatalf("test1: unexpected captured outputs: %q, %q", capturedStdout, capturedStderr)
+	}
+	if !(os.Stdout == stdout && os.Stderr == stderr && log.Writer() == logWriter) {
+		t.Fatalf("test1: unexpected unrestored outputs")
+	}
+	if len(tb1.Helpers) != 1 {
+		t.Fatalf("test1: unexpected Helpers: %#v", tb1.Helpers)
+	}
+	// test2: outputs should be restored on FailNow
+	tb2 := NewHookedTestingTB("test2")
+	tb2.Run("fatal", func(sub *HookedTestingTB) {
+		Capture(sub, func() {
+			fmt.Print("hello")
+			sub.FailNow()
+		})
+	})
+	if !tb2.Failed() {
+		t.Fatalf("test2: unexpected Failed() == false")
+	}
+	if !(os.Stdout == stdout && os.Stderr == stderr && log.Writer() == logWriter) {
+		t.Fatalf("test2: unexpected unrestored outputs")
+	}
+}
+
+func TestAssertOutputMatches(t *testing.T) {
+	// test1: Test helper registration
+	tb1 := NewHookedTestingTB("test1")
+	New(tb1, "hello").OutputMatches("hello")
+	// NOTICE: We cannot identify the location of any helper exactly, because of go cover tool inserts some code into source code files.
+	if len(tb1.Helpers) != 1 {
+		t.Fatalf("test1: unexpected Helpers: %#v", tb1.Helpers)
+	}
+	// test2: only normal uses
+	tb2 := NewHookedTestingTB("test2")
+	New(tb2, `^usage: \w+`).OutputMatches("usage: command [flags]\n")
+	New(tb2, `(?m)^error$`).OutputMatches("hello\nerror\n")
+	if tb2.Failed() {
+		t.Fatalf("test2: unexpected Failed() == true")
+	}
+	if len(tb2.Messages) != 0 {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	// test3: error case followed by fatal exit cases
+	tb3 := NewHookedTestingTB("test3")
+	New(tb3, "^hello$").OutputMatches("hello\n")
+	for _, expected := range [][]interface{}{{}, {1}, {"hello["}} {
+		func() {
+			defer func() {
+				recover()
+			}()
+			New(tb3, expected...).OutputMatches("hello")
+		}()
+	}
+	if !tb3.Failed() {
+		t.Fatalf("test3: unexpected Failed() == false")
+	}
+	if !reflect.DeepEqual(tb3.Messages, []string{
+		"ERROR: expected output pattern \"^hello$\", but got output \"hello\\n\"",
+		"FATAL: the number of output pattern must be one",
+		"FATAL: output pattern must be string",
+		"FATAL: malformed expected output pattern: error parsing regexp: missing closing ]: `[`",
+	}) {
+		t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+	}
+}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package goassert
+
+import (
+	"os"
+	"syscall"
+)
+
+// redirectFD redirects the file descriptor to the file, and returns the function restoring it.
+func redirectFD(fd int, file *os.File) (func(), error) {
+	saved, err := syscall.Dup(fd)
+	if err != nil {
+		return nil, err
+	}
+	if err := dup2(int(file.Fd()), fd); err != nil {
+		syscall.Close(saved)
+		return nil, err
+	}
+	return func() {
+		dup2(saved, fd)
+		syscall.Close(saved)
+	}, nil
+}
@@ -0,0 +1,23 @@
+//go:build unix
+
+package goassert
+
+import (
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestCaptureFD(t *testing.T) {
+	stdout := os.Stdout
+	// test1: outputs written directly to the file descriptors
+	tb1 := NewHookedTestingTB("test1")
+	capturedStdout, capturedStderr := Capture(tb1, func() {
+		syscall.Write(1, []byte("hello"))
+		syscall.Write(2, []byte("world"))
+		stdout.WriteString("!")
+	})
+	if !(capturedStdout == "hello!" && capturedStderr == "world") {
+		t.Fatalf("test1: unexpected captured outputs: %q, %q", capturedStdout, capturedStderr)
+	}
+}
@@ -0,0 +1,141 @@
+package goassert
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// describeChan returns the description of the channel with the element type and the buffer state.
+func describeChan(ch reflect.Value) string {
+	return fmt.Sprintf("%s (%d/%d buffered)", ch.Type(), ch.Len(), ch.Cap())
+}
+
+// chanValue returns the reflected channel which can be used in the direction.
+func (assert *Assert) chanValue(ch interface{}, dir reflect.ChanDir) reflect.Value {
+	assert.tb.Helper()
+	v := reflect.ValueOf(ch)
+	if v.Kind() != reflect.Chan {
+		assert.tb.Fatalf("expected channel, but got %T", ch)
+		return v
+	}
+	if v.Type().ChanDir()&dir == 0 {
+		assert.tb.Fatalf("expected channel which can be used in direction %s, but got %s", reflect.ChanOf(dir, v.Type().Elem()), v.Type())
+		return v
+	}
+	if v.IsNil() {
+		assert.tb.Fatalf("expected channel, but got nil %s", v.Type())
+	}
+	return v
+}
+
+// receive receives a value from the channel within the timeout.
+// The returned values are the received value, whether a value is received and whether the channel is open.
+func receive(ch reflect.Value, timeout time.Duration) (interface{}, bool, bool) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	chosen, v, ok := reflect.Select([]reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: ch},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(timer.C)},
+	})
+	if chosen != 0 {
+		return nil, false, true
+	}
+	if !ok {
+		return nil, false, false
+	}
+	return v.Interface(), true, true
+}
+
+// Receives checks that a value is received from the channel within the timeout, and returns the value.
+func (assert *Assert) Receives(ch interface{}, timeout time.Duration) interface{} {
+	assert.tb.Helper()
+	v := assert.chanValue(ch, reflect.RecvDir)
+	received, ok, open := receive(v, timeout)
+	if !open {
+		assert.tb.Fatalf("expected to receive a value within %s, but %s is closed", timeout, describeChan(v))
+	} else if !ok {
+		assert.tb.Fatalf("expected to receive a value within %s, but received nothing from %s", timeout, describeChan(v))
+	}
+	return received
+}
+
+// ReceivesEqual checks that the expected value is received from the channel within the timeout.
+// The expected values must be one expected value.
+func (assert *Assert) ReceivesEqual(ch interface{}, timeout time.Duration) {
+	assert.tb.Helper()
+	if len(assert.expected) != 1 {
+		assert.tb.Fatalf("the number of the expected values must be one")
+		return
+	}
+	received := assert.Receives(ch, timeout)
+	if equal, _ := assert.equal(assert.expected[0], received); !equal {
+		assert.tb.Errorf("expected %s, but received %s from %s", formatValue(assert.expected[0]), formatValue(received), describeChan(reflect.ValueOf(ch)))
+	}
+}
+
+// NotReceives checks that no value is received from the channel for the duration.
+func (assert *Assert) NotReceives(ch interface{}, d time.Duration) {
+	assert.tb.Helper()
+	v := assert.chanValue(ch, reflect.RecvDir)
+	received, ok, open := receive(v, d)
+	if !open {
+		assert.tb.Errorf("expected to receive nothing within %s, but %s is closed", d, describeChan(v))
+	} else if ok {
+		assert.tb.Errorf("expected to receive nothing within %s, but received %s from %s", d, formatValue(received), describeChan(v))
+	}
+}
+
+// Closed checks that the channel is closed and drained.
+// Closed does not block, so a value buffered in the channel is received and reported.
+func (assert *Assert) Closed(ch interface{}) {
+	assert.tb.Helper()
+	v := assert.chanValue(ch, reflect.RecvDir)
+	chosen, received, ok := reflect.Select([]reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: v},
+		{Dir: reflect.SelectDefault},
+	})
+	if chosen != 0 {
+		assert.tb.Errorf("expected closed channel, but %s is open", describeChan(v))
+	} else if ok {
+		assert.tb.Errorf("expected closed channel, but received %s from %s", formatReflectValue(received), describeChan(v))
+	}
+}
+
+// Sends checks that the value is sent to the channel within the timeout.
+func (assert *Assert) Sends(ch interface{}, value interface{}, timeout time.Duration) {
+	assert.tb.Helper()
+	v := assert.chanValue(ch, reflect.SendDir)
+	elemType := v.Type().Elem()
+	x := reflect.ValueOf(value)
+	if value == nil {
+		switch elemType.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+			x = reflect.Zero(elemType)
+		}
+	}
+	if !x.IsValid() || !x.Type().AssignableTo(elemType) {
+		assert.tb.Fatalf("cannot send %s to %s", formatValue(value), v.Type())
+		return
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	chosen, closed := func() (chosen int, closed bool) {
+		defer func() {
+			// Sending to a closed channel panics.
+			if recover() != nil {
+				closed = true
+			}
+		}()
+		chosen, _, _ = reflect.Select([]reflect.SelectCase{
+			{Dir: reflect.SelectSend, Chan: v, Send: x.Convert(elemType)},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(timer.C)},
+		})
+		return chosen, false
+	}()
+	if closed {
+		assert.tb.Errorf("expected to send %s within %s, but %s is closed", formatValue(value), timeout, describeChan(v))
+	} else if chosen != 0 {
+		assert.tb.Errorf("expected to send %s within %s, but %s is not ready", formatValue(value), timeout, describeChan(v))
+	}
+}
@@ -0,0 +1,226 @@
+package goassert
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAssertReceives(t *testing.T) {
+	// test1: Test helper registration
+	tb1 := NewHookedTestingTB("test1")
+	ch1 := make(chan int, 1)
+	ch1 <- 1
+	New(tb1).Receives(ch1, time.Second)
+	// NOTICE: We cannot identify the location of any helper exactly, because of go cover tool inserts some code into source code files.
+	if len(tb1.Helpers) != 2 {
+		t.Fatalf("test1: unexpected Helpers: %#v", tb1.Helpers)
+	}
+	// test2: only normal uses
+	tb2 := NewHookedTestingTB("test2")
+	ch2 := make(chan string)
+	go func() {
+		ch2 <- "hello"
+	}()
+	if received := New(tb2).Receives((<-chan string)(ch2), time.Second); received != "hello" {
+		t.Fatalf("test2: unexpected received value: %#v", received)
+	}
+	if tb2.Failed() {
+		t.Fatalf("test2: unexpected Failed() == true")
+	}
+	if len(tb2.Messages) != 0 {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	// test3: fatal exit cases
+	ch3 := make(chan int, 3)
+	ch3closed := make(chan int)
+	close(ch3closed)
+	for i, testcase := range []struct {
+		ch      interface{}
+		message string
+	}{
+		{1, "FATAL: expected channel, but got int"},
+		{(chan<- int)(ch3), "FATAL: expected channel which can be used in direction <-chan int, but got chan<- int"},
+		{(chan int)(nil), "FATAL: expected channel, but got nil chan int"},
+		{ch3, "FATAL: expected to receive a value within 1ms, but received nothing from chan int (0/3 buffered)"},
+		{ch3closed, "FATAL: expected to receive a value within 1ms, but chan int (0/0 buffered) is closed"},
+	} {
+		tb3 := NewHookedTestingTB("test3")
+		func() {
+			defer func() {
+				recover()
+			}()
+			New(tb3).Receives(testcase.ch, time.Millisecond)
+		}()
+		if !tb3.Failed() {
+			t.Fatalf("test3 #%d: unexpected Failed() == false", i)
+		}
+		if !reflect.DeepEqual(tb3.Messages, []string{testcase.message}) {
+			t.Fatalf("test3 #%d: unexpected Messages: %#v", i, tb3.Messages)
+		}
+	}
+}
+
+func TestAssertReceivesEqual(t *testing.T) {
+	// test1: Test helper registration
+	tb1 := NewHookedTestingTB("test1")
+	ch1 := make(chan int, 1)
+	ch1 <- 1
+	New(tb1, 1).ReceivesEqual(ch1, time.Second)
+	// NOTICE: We cannot identify the location of any helper exactly, because of go cover tool inserts some code into source code files.
+	if len(tb1.Helpers) != 3 {
+		t.Fatalf("test1: unexpected Helpers: %#v", tb1.Helpers)
+	}
+	// test2: only normal uses
+	tb2 := NewHookedTestingTB("test2")
+	ch2 := make(chan []string, 1)
+	ch2 <- []string{"hello", "world"}
+	New(tb2, []string{"hello", "world"}).ReceivesEqual(ch2, time.Second)
+	if tb2.Failed() {
+		t.Fatalf("test2: unexpected Failed() == true")
+	}
+	if len(tb2.Messages) != 0 {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	// test3: error case followed by one fatal exit case
+	tb3 := NewHookedTestingTB("test3")
+	ch3 := make(chan interface{}, 2)
+	ch3 <- int64(1)
+	ch3 <- 2
+	New(tb3, 1).ReceivesEqual(ch3, time.Second)
+	func() {
+		defer func() {
+			recover()
+		}()
+		New(tb3).ReceivesEqual(ch3, time.Second)
+	}()
+	if !tb3.Failed() {
+		t.Fatalf("test3: unexpected Failed() == false")
+	}
+	if !reflect.DeepEqual(tb3.Messages, []string{
+		"ERROR: expected 1 (int), but received 1 (int64) from chan interface {} (1/2 buffered)",
+		"FATAL: the number of the expected values must be one",
+	}) {
+		t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+	}
+}
+
+func TestAssertNotReceives(t *testing.T) {
+	// test1: Test helper registration
+	tb1 := NewHookedTestingTB("test1")
+	New(tb1).NotReceives(make(chan int), time.Millisecond)
+	// NOTICE: We cannot identify the location of any helper exactly, because of go cover tool inserts some code into source code files.
+	if len(tb1.Helpers) != 2 {
+		t.Fatalf("test1: unexpected Helpers: %#v", tb1.Helpers)
+	}
+	// test2: only normal uses
+	tb2 := NewHookedTestingTB("test2")
+	New(tb2).NotReceives(make(chan struct{}, 1), time.Millisecond)
+	if tb2.Failed() {
+		t.Fatalf("test2: unexpected Failed() == true")
+	}
+	if len(tb2.Messages) != 0 {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	// test3: error cases
+	tb3 := NewHookedTestingTB("test3")
+	ch3 := make(chan string, 2)
+	ch3 <- "hello"
+	New(tb3).NotReceives(ch3, time.Millisecond)
+	close(ch3)
+	New(tb3).NotReceives(ch3, time.Millisecond)
+	if !tb3.Failed() {
+		t.Fatalf("test3: unexpected Failed() == false")
+	}
+	if !reflect.DeepEqual(tb3.Messages, []string{
+		"ERROR: expected to receive nothing within 1ms, but received \"hello\" (string) from chan string (0/2 buffered)",
+		"ERROR: expected to receive nothing within 1ms, but chan string (0/2 buffered) is closed",
+	}) {
+		t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+	}
+}
+
+func TestAssertClosed(t *testing.T) {
+	// test1: Test helper registration
+	tb1 := NewHookedTestingTB("test1")
+	ch1 := make(chan int)
+	close(ch1)
+	New(tb1).Closed(ch1)
+	// NOTICE: We cannot identify the location of any helper exactly, because of go cover tool inserts some code into source code files.
+	if len(tb1.Helpers) != 2 {
+		t.Fatalf("test1: unexpected Helpers: %#v", tb1.Helpers)
+	}
+	// test2: only normal uses
+	tb2 := NewHookedTestingTB("test2")
+	ch2 := make(chan struct{})
+	close(ch2)
+	New(tb2).Closed((<-chan struct{})(ch2))
+	if tb2.Failed() {
+		t.Fatalf("test2: unexpected Failed() == true")
+	}
+	if len(tb2.Messages) != 0 {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	// test3: error cases
+	tb3 := NewHookedTestingTB("test3")
+	ch3 := make(chan int, 1)
+	New(tb3).Closed(ch3)
+	ch3 <- 1
+	close(ch3)
+	New(tb3).Closed(ch3)
+	if !tb3.Failed() {
+		t.Fatalf("test3: unexpected Failed() == false")
+	}
+	if !reflect.DeepEqual(tb3.Messages, []string{
+		"ERROR: expected closed channel, but chan int (0/1 buffered) is open",
+		"ERROR: expected closed channel, but received 1 (int) from chan int (0/1 buffered)",
+	}) {
+		t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+	}
+}
+
+func TestAssertSends(t *testing.T) {
+	// test1: Test helper registration
+	tb1 := NewHookedTestingTB("test1")
+	New(tb1).Sends(make(chan int, 1), 1, time.Second)
+	// NOTICE: We cannot identify the location of any helper exactly, because of go cover tool inserts some code into source code files.
+	if len(tb1.Helpers) != 2 {
+		t.Fatalf("test1: unexpected Helpers: %#v", tb1.Helpers)
+	}
+	// test2: only normal uses
+	tb2 := NewHookedTestingTB("test2")
+	ch2 := make(chan error, 2)
+	New(tb2).Sends((chan<- error)(ch2), nil, time.Second)
+	New(tb2).Sends(ch2, errorString("hello"), time.Second)
+	if tb2.Failed() {
+		t.Fatalf("test2: unexpected Failed() == true")
+	}
+	if len(tb2.Messages) != 0 {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	if err1, err2 := <-ch2, <-ch2; !(err1 == nil && err2 == errorString("hello")) {
+		t.Fatalf("test2: unexpected sent values: %#v, %#v", err1, err2)
+	}
+	// test3: error cases followed by one fatal exit case
+	tb3 := NewHookedTestingTB("test3")
+	ch3 := make(chan int)
+	New(tb3).Sends(ch3, 1, time.Millisecond)
+	close(ch3)
+	New(tb3).Sends(ch3, 2, time.Millisecond)
+	func() {
+		defer func() {
+			recover()
+		}()
+		New(tb3).Sends(ch3, "hello", time.Millisecond)
+	}()
+	if !tb3.Failed() {
+		t.Fatalf("test3: unexpected Failed() == false")
+	}
+	if !reflect.DeepEqual(tb3.Messages, []string{
+		"ERROR: expected to send 1 (int) within 1ms, but chan int (0/0 buffered) is not ready",
+		"ERROR: expected to send 2 (int) within 1ms, but chan int (0/0 buffered) is closed",
+		"FATAL: cannot send \"hello\" (string) to chan int",
+	}) {
+		t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+	}
+}
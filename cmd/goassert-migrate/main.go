@@ -0,0 +1,685 @@
+// Command goassert-migrate rewrites the assertions of testify and the hand-written checks in the Go test files into the ones of package goassert.
+//
+// Usage:
+//
+//	goassert-migrate [flags] [file|dir]...
+//
+// The directory means the test files in it.
+// The following patterns are rewritten, and the other uses of testify and the hand-written checks which cannot be rewritten are reported:
+//
+//	assert.Equal(t, expected, actual)            goassert.New(t, expected).Equal(actual)
+//	assert.True(t, cond)                         goassert.New(t, true).Equal(cond)
+//	assert.False(t, cond)                        goassert.New(t, false).Equal(cond)
+//	assert.NoError(t, err)                       goassert.New(t).SucceedWithoutError(err)
+//	require.Error(t, err)                        goassert.New(t).ExpectError(err)
+//	require.EqualError(t, err, "message")        goassert.New(t, "^message$").ExpectError(err)
+//	if got != want { t.Errorf(...) }             goassert.New(t, want).Equal(got)
+//	if !reflect.DeepEqual(got, want) { t.Errorf(...) }
+//	                                             goassert.New(t, want).Equal(got)
+//	if err != nil { t.Error(err) }               goassert.New(t).SucceedWithoutError(err)
+//	if err == nil { t.Fatal("...") }             goassert.New(t).ExpectError(err)
+//
+// The hand-written checks may call any of t.Error, t.Errorf, t.Fatal and t.Fatalf.
+// Only ExpectError stops the test at the failure, so the calls of require and the checks calling t.Fatal or t.Fatalf are rewritten only into ExpectError,
+// and the calls of assert and the checks calling t.Error or t.Errorf are never rewritten into ExpectError; the others are reported.
+// The expected value of the hand-written check is the operand named like "want" or "expected", or the literal; otherwise it is the second operand.
+// The comparison by operator != is rewritten only if the operands have the identical types, and the untyped constant is converted to the type of the other operand, e.g. int64(3).
+// The files are type-checked with the other files of the same package in the directory.
+// The comments in the rewritten statements are moved before them, and the imports of testify and reflect are removed if they are no longer used.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Import paths of the packages handled in the migration.
+const (
+	goassertPath       = "github.com/hiro4bbh/go-assert"
+	testifyAssertPath  = "github.com/stretchr/testify/assert"
+	testifyRequirePath = "github.com/stretchr/testify/require"
+)
+
+// problem is the pattern which cannot be rewritten.
+type problem struct {
+	Pos     token.Position
+	Message string
+}
+
+// String returns the problem in the format "file:line:column: message".
+func (p problem) String() string {
+	return fmt.Sprintf("%s: %s", p.Pos, p.Message)
+}
+
+// edit is the replacement of the source code in the byte range [start, end).
+type edit struct {
+	start, end int
+	text       string
+}
+
+// applyEdits returns the source code with the non-overlapping edits applied.
+func applyEdits(src []byte, edits []edit) []byte {
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].start < edits[j].start
+	})
+	var buf bytes.Buffer
+	offset := 0
+	for _, e := range edits {
+		buf.Write(src[offset:e.start])
+		buf.WriteString(e.text)
+		offset = e.end
+	}
+	buf.Write(src[offset:])
+	return buf.Bytes()
+}
+
+// importNames returns the map of the import paths to the names of the imports in the file.
+func importNames(file *ast.File) map[string]string {
+	names := map[string]string{}
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if spec.Name != nil {
+			names[path] = spec.Name.Name
+		} else {
+			names[path] = path[strings.LastIndex(path, "/")+1:]
+		}
+	}
+	return names
+}
+
+// migrator rewrites the file.
+type migrator struct {
+	fset     *token.FileSet
+	file     *ast.File
+	src      []byte
+	imports  map[string]string
+	pkg      *types.Package
+	info     *types.Info
+	goassert string
+	edits    []edit
+	problems []problem
+}
+
+// text returns the source code of the node.
+func (m *migrator) text(node ast.Node) string {
+	return string(m.src[m.fset.Position(node.Pos()).Offset:m.fset.Position(node.End()).Offset])
+}
+
+// reportf records the problem at the position.
+func (m *migrator) reportf(pos token.Pos, format string, args ...interface{}) {
+	m.problems = append(m.problems, problem{Pos: m.fset.Position(pos), Message: fmt.Sprintf(format, args...)})
+}
+
+// replace records the edit replacing the statement with the rewritten one.
+// The comments in the statement are moved before the rewritten one.
+func (m *migrator) replace(stmt ast.Stmt, format string, args ...interface{}) {
+	comments := ""
+	for _, group := range m.file.Comments {
+		if group.Pos() > stmt.Pos() && group.End() < stmt.End() {
+			for _, comment := range group.List {
+				comments += comment.Text + "\n"
+			}
+		}
+	}
+	m.edits = append(m.edits, edit{
+		start: m.fset.Position(stmt.Pos()).Offset,
+		end:   m.fset.Position(stmt.End()).Offset,
+		text:  comments + fmt.Sprintf(format, args...),
+	})
+}
+
+// packageCall returns the package name and the function name if the expression is the call of the function of the imported package.
+func (m *migrator) packageCall(x ast.Expr) (*ast.CallExpr, string, string) {
+	call, ok := x.(*ast.CallExpr)
+	if !ok {
+		return nil, "", ""
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil, "", ""
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok || pkg.Obj != nil {
+		return nil, "", ""
+	}
+	return call, pkg.Name, sel.Sel.Name
+}
+
+// isTestify returns true if the package name is the import name of testify assert or require.
+func (m *migrator) isTestify(name string) bool {
+	for _, path := range []string{testifyAssertPath, testifyRequirePath} {
+		if importName, ok := m.imports[path]; ok && importName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// testifyArgs is the numbers of the arguments except for the testing context of the supported testify functions.
+var testifyArgs = map[string]int{
+	"Equal":      2,
+	"True":       1,
+	"False":      1,
+	"NoError":    1,
+	"Error":      1,
+	"EqualError": 2,
+}
+
+// migrateTestify rewrites the statement calling the function of testify.
+func (m *migrator) migrateTestify(stmt *ast.ExprStmt, call *ast.CallExpr, pkg, fn string) {
+	n, ok := testifyArgs[fn]
+	if !ok {
+		m.reportf(call.Pos(), "cannot convert %s.%s: unsupported function", pkg, fn)
+		return
+	}
+	// Only ExpectError stops the test at the failure as require does.
+	fatal, expectError := pkg == m.imports[testifyRequirePath], fn == "Error" || fn == "EqualError"
+	if fatal && !expectError {
+		m.reportf(call.Pos(), "cannot convert %s.%s: the rewritten assertion does not stop the test", pkg, fn)
+		return
+	} else if !fatal && expectError {
+		m.reportf(call.Pos(), "cannot convert %s.%s: the rewritten assertion stops the test", pkg, fn)
+		return
+	}
+	if call.Ellipsis.IsValid() || len(call.Args) != n+1 {
+		m.reportf(call.Pos(), "cannot convert %s.%s: unsupported message arguments", pkg, fn)
+		return
+	}
+	tb, args := m.text(call.Args[0]), call.Args[1:]
+	switch fn {
+	case "Equal":
+		m.replace(stmt, "%s.New(%s, %s).Equal(%s)", m.goassert, tb, m.text(args[0]), m.text(args[1]))
+	case "True", "False":
+		m.replace(stmt, "%s.New(%s, %s).Equal(%s)", m.goassert, tb, strings.ToLower(fn), m.text(args[0]))
+	case "NoError":
+		m.replace(stmt, "%s.New(%s).SucceedWithoutError(%s)", m.goassert, tb, m.text(args[0]))
+	case "Error":
+		m.replace(stmt, "%s.New(%s).ExpectError(%s)", m.goassert, tb, m.text(args[0]))
+	case "EqualError":
+		lit, ok := args[1].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			m.reportf(call.Pos(), "cannot convert %s.%s: the error message must be a string literal", pkg, fn)
+			return
+		}
+		message, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			m.reportf(call.Pos(), "cannot convert %s.%s: %s", pkg, fn, err)
+			return
+		}
+		m.replace(stmt, "%s.New(%s, %s).ExpectError(%s)", m.goassert, tb, strconv.Quote("^"+regexp.QuoteMeta(message)+"$"), m.text(args[0]))
+	}
+}
+
+// failureCall returns the testing context and the method name if the block is the only call of its method Error, Errorf, Fatal or Fatalf.
+// The testing context must be the local variable or parameter such as t.
+func (m *migrator) failureCall(body *ast.BlockStmt) (string, string, bool) {
+	if len(body.List) != 1 {
+		return "", "", false
+	}
+	stmt, ok := body.List[0].(*ast.ExprStmt)
+	if !ok {
+		return "", "", false
+	}
+	call, ok := stmt.X.(*ast.CallExpr)
+	if !ok {
+		return "", "", false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", "", false
+	}
+	if tb, ok := sel.X.(*ast.Ident); !ok || tb.Obj == nil {
+		return "", "", false
+	}
+	switch sel.Sel.Name {
+	case "Error", "Errorf", "Fatal", "Fatalf":
+		return m.text(sel.X), sel.Sel.Name, true
+	}
+	return "", "", false
+}
+
+// isExpected returns true if the expression looks like the expected value, which is the literal or the identifier named like "want" or "expected".
+func isExpected(x ast.Expr) bool {
+	switch x := x.(type) {
+	case *ast.BasicLit:
+		return true
+	case *ast.Ident:
+		name := strings.ToLower(x.Name)
+		return strings.Contains(name, "want") || strings.HasPrefix(name, "exp")
+	}
+	return false
+}
+
+// isErrorName returns true if the expression is the identifier named like an error.
+func isErrorName(x ast.Expr) bool {
+	ident, ok := x.(*ast.Ident)
+	return ok && (ident.Name == "err" || strings.HasSuffix(ident.Name, "Err"))
+}
+
+// isNil returns true if the expression is nil.
+func isNil(x ast.Expr) bool {
+	ident, ok := x.(*ast.Ident)
+	return ok && ident.Name == "nil" && ident.Obj == nil
+}
+
+// migrateCheck rewrites the hand-written check, which is the if statement calling only the failure method.
+// The check calling Fatal or Fatalf is rewritten only into ExpectError, which stops the test too, and vice versa.
+func (m *migrator) migrateCheck(stmt *ast.IfStmt, tb, method string) {
+	if stmt.Init != nil || stmt.Else != nil {
+		m.reportf(stmt.Pos(), "cannot convert the hand-written check: unsupported init or else statement")
+		return
+	}
+	cond := ast.Unparen(stmt.Cond)
+	fatal := method == "Fatal" || method == "Fatalf"
+	if binary, ok := cond.(*ast.BinaryExpr); ok && binary.Op == token.EQL {
+		x, y := ast.Unparen(binary.X), ast.Unparen(binary.Y)
+		if isNil(x) {
+			x, y = y, x
+		}
+		if isNil(y) && isErrorName(x) {
+			if !fatal {
+				m.reportf(stmt.Pos(), "cannot convert the hand-written check: the rewritten assertion stops the test unlike %s.%s", tb, method)
+				return
+			}
+			m.replace(stmt, "%s.New(%s).ExpectError(%s)", m.goassert, tb, m.text(x))
+			return
+		}
+	}
+	if fatal {
+		m.reportf(stmt.Pos(), "cannot convert the hand-written check: the rewritten assertion does not stop the test unlike %s.%s", tb, method)
+		return
+	}
+	switch cond := cond.(type) {
+	case *ast.BinaryExpr:
+		x, y := ast.Unparen(cond.X), ast.Unparen(cond.Y)
+		if isNil(x) {
+			x, y = y, x
+		}
+		switch {
+		case cond.Op == token.NEQ && isNil(y) && isErrorName(x):
+			m.replace(stmt, "%s.New(%s).SucceedWithoutError(%s)", m.goassert, tb, m.text(x))
+			return
+		case cond.Op == token.NEQ && !isNil(y):
+			m.migrateComparison(stmt, tb, cond.X, cond.Y)
+			return
+		}
+	case *ast.UnaryExpr:
+		if call, pkg, fn := m.packageCall(ast.Unparen(cond.X)); cond.Op == token.NOT && call != nil && pkg == m.imports["reflect"] && fn == "DeepEqual" && len(call.Args) == 2 {
+			m.migrateEqual(stmt, tb, call.Args[0], call.Args[1])
+			return
+		}
+	}
+	m.reportf(stmt.Pos(), "cannot convert the hand-written check: unsupported condition %s", m.text(stmt.Cond))
+}
+
+// migrateEqual rewrites the hand-written check comparing the two values.
+func (m *migrator) migrateEqual(stmt *ast.IfStmt, tb string, x, y ast.Expr) {
+	if isExpected(ast.Unparen(x)) && !isExpected(ast.Unparen(y)) {
+		x, y = y, x
+	}
+	m.replace(stmt, "%s.New(%s, %s).Equal(%s)", m.goassert, tb, m.text(y), m.text(x))
+}
+
+// constantType returns the type of the constant expression passed as interface{}, which is the default type of its kind, or nil if the expression is not constant.
+// go/types records the untyped constant compared with the typed operand as the type of the operand, so the type is derived from the constant value.
+func constantType(x ast.Expr, tv types.TypeAndValue) types.Type {
+	if tv.Value == nil {
+		return nil
+	}
+	if lit, ok := ast.Unparen(x).(*ast.BasicLit); ok && lit.Kind == token.CHAR {
+		return types.Typ[types.Rune]
+	}
+	switch tv.Value.Kind() {
+	case constant.Bool:
+		return types.Typ[types.Bool]
+	case constant.String:
+		return types.Typ[types.String]
+	case constant.Int:
+		return types.Typ[types.Int]
+	case constant.Float:
+		return types.Typ[types.Float64]
+	case constant.Complex:
+		return types.Typ[types.Complex128]
+	}
+	return nil
+}
+
+// qualifier returns the name qualifying the type of the package in the file, or false if the package is not imported.
+func (m *migrator) qualifier(pkg *types.Package) (string, bool) {
+	if pkg == nil || pkg == m.pkg {
+		return "", true
+	}
+	name, ok := m.imports[pkg.Path()]
+	return name, ok
+}
+
+// errorInterface is the type of the interface error.
+var errorInterface = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+// identityReason returns the reason why != on the values of the type cannot be rewritten into Equal, or the empty string if it can be.
+// The values compared by identity at least partly, e.g. the pointers, interfaces and channels, are not rewritten,
+// because Equal compares them deeply and changes what the check means.
+func identityReason(t types.Type, visited map[types.Type]bool) string {
+	if visited[t] {
+		return ""
+	}
+	visited[t] = true
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		return "pointers are compared by identity"
+	case *types.Basic:
+		if u.Kind() == types.UnsafePointer {
+			return "pointers are compared by identity"
+		}
+	case *types.Interface:
+		if types.Implements(t, errorInterface) {
+			return "errors are compared by identity (use errors.Is instead)"
+		}
+		return "interfaces are compared by the identity of the pointers in their dynamic values"
+	case *types.Chan:
+		return "channels are compared by identity"
+	case *types.Signature:
+		return "functions are comparable only with nil"
+	case *types.Map:
+		return "maps are comparable only with nil"
+	case *types.Slice:
+		return "slices are comparable only with nil"
+	case *types.Array:
+		if identityReason(u.Elem(), visited) != "" {
+			return fmt.Sprintf("the elements of %s are compared by identity", t)
+		}
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			if identityReason(u.Field(i).Type(), visited) != "" {
+				return fmt.Sprintf("field %s of %s is compared by identity", u.Field(i).Name(), t)
+			}
+		}
+	}
+	return ""
+}
+
+// migrateComparison rewrites the hand-written check comparing the two values by operator !=.
+// The comparison is rewritten only if the operands have the identical types, because Equal compares the types strictly.
+// The untyped constant is converted to the type of the other operand, and the values compared by identity are not rewritten, see identityReason.
+func (m *migrator) migrateComparison(stmt *ast.IfStmt, tb string, x, y ast.Expr) {
+	tx, ty := m.info.Types[x], m.info.Types[y]
+	if tx.Type == nil || ty.Type == nil {
+		m.reportf(stmt.Pos(), "cannot convert the hand-written check: unknown types of operands in %s", m.text(stmt.Cond))
+		return
+	}
+	for _, t := range []types.Type{tx.Type, ty.Type} {
+		if reason := identityReason(t, map[types.Type]bool{}); reason != "" {
+			m.reportf(stmt.Pos(), "cannot convert the hand-written check: %s in %s", reason, m.text(stmt.Cond))
+			return
+		}
+	}
+	cx, cy := constantType(x, tx), constantType(y, ty)
+	if (cx == nil) != (cy == nil) {
+		constExpr, constType, typed, typedType := x, cx, y, ty.Type
+		if cy != nil {
+			constExpr, constType, typed, typedType = y, cy, x, tx.Type
+		}
+		if !types.Identical(constType, typedType) {
+			qualified := true
+			typeName := types.TypeString(typedType, func(pkg *types.Package) string {
+				name, ok := m.qualifier(pkg)
+				qualified = qualified && ok
+				return name
+			})
+			if !qualified {
+				m.reportf(stmt.Pos(), "cannot convert the hand-written check: type %s is not accessible in %s", typedType, m.text(stmt.Cond))
+				return
+			}
+			m.replace(stmt, "%s.New(%s, %s(%s)).Equal(%s)", m.goassert, tb, typeName, m.text(constExpr), m.text(typed))
+			return
+		}
+	} else if !types.Identical(tx.Type, ty.Type) {
+		m.reportf(stmt.Pos(), "cannot convert the hand-written check: different types %s and %s in %s", tx.Type, ty.Type, m.text(stmt.Cond))
+		return
+	}
+	m.migrateEqual(stmt, tb, x, y)
+}
+
+// migrateStmts rewrites the statements in the file.
+func (m *migrator) migrateStmts() {
+	ast.Inspect(m.file, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.ExprStmt:
+			if call, pkg, fn := m.packageCall(node.X); call != nil && m.isTestify(pkg) {
+				m.migrateTestify(node, call, pkg, fn)
+				return false
+			}
+		case *ast.IfStmt:
+			if tb, method, ok := m.failureCall(node.Body); ok {
+				m.migrateCheck(node, tb, method)
+				return false
+			}
+		case *ast.CallExpr:
+			if call, pkg, fn := m.packageCall(node); call != nil && m.isTestify(pkg) {
+				m.reportf(call.Pos(), "cannot convert %s.%s: the result is used", pkg, fn)
+			}
+		}
+		return true
+	})
+}
+
+// usedNames returns the set of the package names used in the file.
+func usedNames(file *ast.File) map[string]bool {
+	used := map[string]bool{}
+	ast.Inspect(file, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Obj == nil {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+	return used
+}
+
+// lineRange returns the byte range of the lines containing the node.
+func lineRange(fset *token.FileSet, src []byte, node ast.Node) (int, int) {
+	start, end := fset.Position(node.Pos()).Offset, fset.Position(node.End()).Offset
+	for start > 0 && src[start-1] != '\n' {
+		start--
+	}
+	for end < len(src) && src[end] != '\n' {
+		end++
+	}
+	if end < len(src) {
+		end++
+	}
+	return start, end
+}
+
+// fixImports returns the source code whose imports of testify and reflect are removed if unused, and the import of goassert is added if missing.
+// The import of goassert replaces the first removed import of testify if any.
+func fixImports(fset *token.FileSet, filename string, src []byte, goassert string) ([]byte, error) {
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	importSpec := ""
+	if _, ok := importNames(file)[goassertPath]; !ok {
+		importSpec = fmt.Sprintf("%s %q", goassert, goassertPath)
+	}
+	used := usedNames(file)
+	edits := []edit{}
+	var lastImport *ast.GenDecl
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		lastImport = gen
+		for _, spec := range gen.Specs {
+			spec := spec.(*ast.ImportSpec)
+			path, _ := strconv.Unquote(spec.Path.Value)
+			if path != testifyAssertPath && path != testifyRequirePath && path != "reflect" {
+				continue
+			}
+			if name := importNames(&ast.File{Imports: []*ast.ImportSpec{spec}})[path]; used[name] {
+				continue
+			}
+			node, prefix := ast.Node(spec), "\t"
+			if !gen.Lparen.IsValid() {
+				node, prefix = gen, "import "
+			}
+			e := edit{}
+			e.start, e.end = lineRange(fset, src, node)
+			if path != "reflect" && importSpec != "" {
+				e.text, importSpec = prefix+importSpec+"\n", ""
+			}
+			edits = append(edits, e)
+		}
+	}
+	if importSpec != "" {
+		switch {
+		case lastImport == nil:
+			offset := fset.Position(file.Name.End()).Offset
+			edits = append(edits, edit{start: offset, end: offset, text: "\n\nimport " + importSpec})
+		case lastImport.Lparen.IsValid():
+			offset := fset.Position(lastImport.Rparen).Offset
+			edits = append(edits, edit{start: offset, end: offset, text: "\n" + importSpec + "\n"})
+		default:
+			spec := lastImport.Specs[0]
+			text := fmt.Sprintf("import (\n\t%s\n\n\t%s\n)", src[fset.Position(spec.Pos()).Offset:fset.Position(spec.End()).Offset], importSpec)
+			edits = append(edits, edit{start: fset.Position(lastImport.Pos()).Offset, end: fset.Position(lastImport.End()).Offset, text: text})
+		}
+	}
+	return applyEdits(src, edits), nil
+}
+
+// typeCheck type-checks the file with the other files of the same package in its directory.
+// The type errors are ignored, so the types of the expressions depending on the unresolved declarations are unknown.
+func typeCheck(fset *token.FileSet, filename string, file *ast.File) (*types.Package, *types.Info) {
+	files := []*ast.File{file}
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(filename), "*.go"))
+	for _, match := range matches {
+		if filepath.Base(match) == filepath.Base(filename) {
+			continue
+		}
+		if other, err := parser.ParseFile(fset, match, nil, 0); err == nil && other.Name.Name == file.Name.Name {
+			files = append(files, other)
+		}
+	}
+	info := &types.Info{Types: map[ast.Expr]types.TypeAndValue{}}
+	config := &types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(err error) {},
+	}
+	pkg, _ := config.Check(file.Name.Name, fset, files, info)
+	return pkg, info
+}
+
+// migrate returns the rewritten source code of the file and the problems which cannot be rewritten.
+func migrate(filename string, src []byte) ([]byte, []problem, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+	m := &migrator{
+		fset:     fset,
+		file:     file,
+		src:      src,
+		imports:  importNames(file),
+		goassert: "goassert",
+	}
+	m.pkg, m.info = typeCheck(fset, filename, file)
+	if name, ok := m.imports[goassertPath]; ok {
+		m.goassert = name
+	}
+	m.migrateStmts()
+	if len(m.edits) == 0 {
+		return src, m.problems, nil
+	}
+	migrated, err := fixImports(token.NewFileSet(), filename, applyEdits(src, m.edits), m.goassert)
+	if err != nil {
+		return nil, nil, err
+	}
+	formatted, err := format.Source(migrated)
+	if err != nil {
+		return nil, nil, err
+	}
+	return formatted, m.problems, nil
+}
+
+// expandFiles returns the files of the arguments, where the directory means the test files in it.
+func expandFiles(args []string) ([]string, error) {
+	files := []string{}
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(arg, "*_test.go"))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+func main() {
+	write := flag.Bool("w", false, "write the result to the file instead of stdout")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: goassert-migrate [flags] [file|dir]...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	args := flag.Args()
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+	files, err := expandFiles(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "goassert-migrate: %s\n", err)
+		os.Exit(2)
+	}
+	for _, filename := range files {
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "goassert-migrate: %s\n", err)
+			os.Exit(1)
+		}
+		migrated, problems, err := migrate(filename, src)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "goassert-migrate: cannot migrate %s: %s\n", filename, err)
+			os.Exit(1)
+		}
+		for _, p := range problems {
+			fmt.Fprintln(os.Stderr, p)
+		}
+		if !*write {
+			os.Stdout.Write(migrated)
+		} else if !bytes.Equal(src, migrated) {
+			if err := os.WriteFile(filename, migrated, 0644); err != nil {
+				fmt.Fprintf(os.Stderr, "goassert-migrate: %s\n", err)
+				os.Exit(1)
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	src, err := os.ReadFile("testdata/example_test.input")
+	if err != nil {
+		t.Fatalf("test: cannot read input: %s", err)
+	}
+	migrated, problems, err := migrate("example_test.go", src)
+	if err != nil {
+		t.Fatalf("test: cannot migrate: %s", err)
+	}
+	expected, err := os.ReadFile("testdata/example_test.golden")
+	if err != nil {
+		t.Fatalf("test: cannot read golden file: %s", err)
+	}
+	if string(expected) != string(migrated) {
+		t.Fatalf("test: expected testdata/example_test.golden, but got:\n%s", migrated)
+	}
+	messages := []string{}
+	for _, p := range problems {
+		messages = append(messages, p.String())
+	}
+	if expected := []string{
+		"example_test.go:15:2: cannot convert require.NoError: the rewritten assertion does not stop the test",
+		"example_test.go:16:2: cannot convert assert.Error: the rewritten assertion stops the test",
+		"example_test.go:19:2: cannot convert require.False: the rewritten assertion does not stop the test",
+		"example_test.go:20:2: cannot convert assert.Equal: unsupported message arguments",
+		"example_test.go:21:2: cannot convert assert.Contains: unsupported function",
+		"example_test.go:22:6: cannot convert assert.True: the result is used",
+		"example_test.go:32:2: cannot convert the hand-written check: the rewritten assertion does not stop the test unlike t.Fatalf",
+		"example_test.go:44:2: cannot convert the hand-written check: pointers are compared by identity in p != &n",
+		"example_test.go:50:2: cannot convert the hand-written check: unknown types of operands in n != unknown()",
+		"example_test.go:60:2: cannot convert the hand-written check: the rewritten assertion does not stop the test unlike t.Fatal",
+		"example_test.go:66:2: cannot convert the hand-written check: the rewritten assertion stops the test unlike t.Error",
+		"example_test.go:69:2: cannot convert the hand-written check: errors are compared by identity (use errors.Is instead) in err != errNotFound",
+		"example_test.go:73:2: cannot convert the hand-written check: channels are compared by identity in ch != ch",
+		"example_test.go:76:2: cannot convert the hand-written check: field next of example.node is compared by identity in (node{}) != (node{})",
+		"example_test.go:79:2: cannot convert the hand-written check: unsupported condition got > want",
+	}; !reflect.DeepEqual(messages, expected) {
+		t.Fatalf("test: unexpected problems: %#v", messages)
+	}
+}
+
+func TestMigrateSingleImport(t *testing.T) {
+	src := "package example\n\nimport \"testing\"\n\nfunc TestF(t *testing.T) {\n\tif x := 1; x != 1 {\n\t\tt.Error(\"unexpected\")\n\t}\n\tvar err error\n\tif err != nil {\n\t\tt.Error(err)\n\t}\n}\n"
+	migrated, _, err := migrate("example_test.go", []byte(src))
+	if err != nil {
+		t.Fatalf("test: cannot migrate: %s", err)
+	}
+	expected := "package example\n\nimport (\n\t\"testing\"\n\n\tgoassert \"github.com/hiro4bbh/go-assert\"\n)\n\nfunc TestF(t *testing.T) {\n\tif x := 1; x != 1 {\n\t\tt.Error(\"unexpected\")\n\t}\n\tvar err error\n\tgoassert.New(t).SucceedWithoutError(err)\n}\n"
+	if string(migrated) != expected {
+		t.Fatalf("test: expected %q, but got %q", expected, migrated)
+	}
+}
@@ -0,0 +1,286 @@
+// Command goassert-mockgen generates the mock of the interface for package goassert.
+//
+// Usage:
+//
+//	goassert-mockgen [flags] InterfaceName
+//
+// The generated mock embeds goassert.Mock, records the calls of the methods, and returns the values of the matched expectations.
+// The variadic arguments are recorded as one slice argument.
+// It is suitable for go:generate, for example:
+//
+//	//go:generate goassert-mockgen -out store_mock_test.go Store
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// goassertPath is the import path of package goassert.
+const goassertPath = "github.com/hiro4bbh/go-assert"
+
+// loadPackage parses and type-checks the non-test Go files in the directory as the package of the import path.
+func loadPackage(dir, importPath string) (*types.Package, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		names := []string{}
+		for name := range pkgs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("expected one package in %s, but got %q", dir, names)
+	}
+	var pkgName string
+	var files []*ast.File
+	for name, pkg := range pkgs {
+		pkgName = name
+		fileNames := []string{}
+		for fileName := range pkg.Files {
+			fileNames = append(fileNames, fileName)
+		}
+		sort.Strings(fileNames)
+		for _, fileName := range fileNames {
+			files = append(files, pkg.Files[fileName])
+		}
+	}
+	config := &types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if importPath == "" {
+		importPath = pkgName
+	}
+	return config.Check(importPath, fset, files, nil)
+}
+
+// generator generates the source code of the mock.
+type generator struct {
+	pkg     *types.Package
+	imports map[string]string
+	buf     bytes.Buffer
+}
+
+// qualifier returns the package name qualifying the type in the generated code, and records its import.
+func (g *generator) qualifier(pkg *types.Package) string {
+	if pkg == g.pkg {
+		return ""
+	}
+	if name, ok := g.imports[pkg.Path()]; ok {
+		return name
+	}
+	name := pkg.Name()
+	for i := 2; g.nameUsed(name); i++ {
+		name = fmt.Sprintf("%s%d", pkg.Name(), i)
+	}
+	g.imports[pkg.Path()] = name
+	return name
+}
+
+// nameUsed returns true if the import name is already used.
+func (g *generator) nameUsed(name string) bool {
+	for _, used := range g.imports {
+		if used == name {
+			return true
+		}
+	}
+	return false
+}
+
+// typeString returns the type in the generated code.
+func (g *generator) typeString(t types.Type) string {
+	return types.TypeString(t, g.qualifier)
+}
+
+// printf writes the formatted string to the generated code.
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// paramName returns the name of the i-th parameter in the generated code, and records it in the used parameter names.
+// The name is replaced if it is blank or conflicts with the names used in the generated method or the other parameters.
+func (g *generator) paramName(v *types.Var, i int, used map[string]bool) string {
+	name := v.Name()
+	if _, err := strconv.Atoi(strings.TrimPrefix(name, "r")); err == nil || name == "" || name == "_" || name == "m" || name == "results" || name == "v" || g.nameUsed(name) || used[name] {
+		name = fmt.Sprintf("arg%d", i)
+		for n := i + 1; used[name]; n++ {
+			name = fmt.Sprintf("arg%d", n)
+		}
+	}
+	used[name] = true
+	return name
+}
+
+// generateMethod generates the mocked method.
+func (g *generator) generateMethod(mockName, typeArgs string, method *types.Func) {
+	sig := method.Type().(*types.Signature)
+	// The types are resolved before the parameter names to avoid the conflicts with the import names.
+	paramTypes := []string{}
+	for i := 0; i < sig.Params().Len(); i++ {
+		if t := sig.Params().At(i).Type(); sig.Variadic() && i == sig.Params().Len()-1 {
+			paramTypes = append(paramTypes, "..."+g.typeString(t.(*types.Slice).Elem()))
+		} else {
+			paramTypes = append(paramTypes, g.typeString(t))
+		}
+	}
+	results := []string{}
+	for i := 0; i < sig.Results().Len(); i++ {
+		results = append(results, g.typeString(sig.Results().At(i).Type()))
+	}
+	params, args, used := []string{}, []string{strconv.Quote(method.Name())}, map[string]bool{}
+	for i, paramType := range paramTypes {
+		name := g.paramName(sig.Params().At(i), i, used)
+		params = append(params, name+" "+paramType)
+		args = append(args, name)
+	}
+	resultsStr := strings.Join(results, ", ")
+	if len(results) > 1 {
+		resultsStr = "(" + resultsStr + ")"
+	}
+	if len(results) == 0 {
+		g.printf("\n// %s records the call.\n", method.Name())
+	} else {
+		g.printf("\n// %s records the call, and returns the values of the matched expectation.\n", method.Name())
+	}
+	g.printf("func (m *%s%s) %s(%s) %s {\n", mockName, typeArgs, method.Name(), strings.Join(params, ", "), resultsStr)
+	if len(results) == 0 {
+		g.printf("m.Mock.MethodCalled(%s)\n}\n", strings.Join(args, ", "))
+		return
+	}
+	g.printf("results := m.Mock.MethodCalled(%s)\n", strings.Join(args, ", "))
+	names := []string{}
+	for i, result := range results {
+		name := fmt.Sprintf("r%d", i)
+		g.printf("var %s %s\n", name, result)
+		g.printf("if v := results.Get(%d); v != nil {\n%s = v.(%s)\n}\n", i, name, result)
+		names = append(names, name)
+	}
+	g.printf("return %s\n}\n", strings.Join(names, ", "))
+}
+
+// generate generates the source code of the mock of the interface in the package named pkgName.
+func generate(pkg *types.Package, ifaceName, mockName, pkgName string) ([]byte, error) {
+	obj := pkg.Scope().Lookup(ifaceName)
+	if obj == nil {
+		return nil, fmt.Errorf("%s is not found in package %s", ifaceName, pkg.Name())
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a named type", ifaceName)
+	}
+	iface, ok := named.Underlying().(*types.Interface)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an interface", ifaceName)
+	}
+	if !iface.IsMethodSet() {
+		return nil, fmt.Errorf("%s is a constraint interface", ifaceName)
+	}
+	for i := 0; i < iface.NumMethods(); i++ {
+		if iface.Method(i).Name() == "Mock" {
+			return nil, fmt.Errorf("method Mock of %s conflicts with the embedded goassert.Mock", ifaceName)
+		}
+	}
+	g := &generator{imports: map[string]string{goassertPath: "goassert"}}
+	if pkgName == pkg.Name() {
+		g.pkg = pkg
+	}
+	typeParams, typeArgs := "", ""
+	if tparams := named.TypeParams(); tparams.Len() > 0 {
+		params, args := []string{}, []string{}
+		for i := 0; i < tparams.Len(); i++ {
+			tparam := tparams.At(i)
+			params = append(params, fmt.Sprintf("%s %s", tparam.Obj().Name(), g.typeString(tparam.Constraint())))
+			args = append(args, tparam.Obj().Name())
+		}
+		typeParams, typeArgs = "["+strings.Join(params, ", ")+"]", "["+strings.Join(args, ", ")+"]"
+	}
+	ifaceStr := ifaceName
+	if g.pkg == nil {
+		ifaceStr = pkg.Name() + "." + ifaceName
+	}
+	g.printf("\n// %s is the mock of %s.\n", mockName, ifaceStr)
+	g.printf("type %s%s struct {\ngoassert.Mock\n}\n", mockName, typeParams)
+	g.printf("\n// New%s returns a new %s initialized with the testing context.\n", mockName, mockName)
+	g.printf("func New%s%s(tb goassert.TestingTB) *%s%s {\nm := &%s%s{}\nm.Mock.Init(tb)\nreturn m\n}\n", mockName, typeParams, mockName, typeArgs, mockName, typeArgs)
+	for i := 0; i < iface.NumMethods(); i++ {
+		g.generateMethod(mockName, typeArgs, iface.Method(i))
+	}
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by goassert-mockgen; DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkgName)
+	paths := []string{}
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if name := g.imports[path]; name != filepath.Base(path) {
+			fmt.Fprintf(&src, "%s %q\n", name, path)
+		} else {
+			fmt.Fprintf(&src, "%q\n", path)
+		}
+	}
+	fmt.Fprintf(&src, ")\n%s", g.buf.Bytes())
+	return format.Source(src.Bytes())
+}
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the package containing the interface")
+	out := flag.String("out", "", "output file (default stdout)")
+	mockName := flag.String("mock", "", "name of the mock type (default Mock + the interface name)")
+	pkgName := flag.String("pkg", "", "package name of the generated code (default the package name of the interface)")
+	importPath := flag.String("importpath", "", "import path of the package containing the interface (default detected by go/build)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: goassert-mockgen [flags] InterfaceName\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	ifaceName := flag.Arg(0)
+	if *mockName == "" {
+		*mockName = "Mock" + ifaceName
+	}
+	if *importPath == "" {
+		if bpkg, err := build.ImportDir(*dir, build.FindOnly); err == nil && bpkg.ImportPath != "." {
+			*importPath = bpkg.ImportPath
+		}
+	}
+	pkg, err := loadPackage(*dir, *importPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "goassert-mockgen: cannot load package: %s\n", err)
+		os.Exit(1)
+	}
+	if *pkgName == "" {
+		*pkgName = pkg.Name()
+	}
+	src, err := generate(pkg, ifaceName, *mockName, *pkgName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "goassert-mockgen: %s\n", err)
+		os.Exit(1)
+	}
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := os.WriteFile(*out, src, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "goassert-mockgen: %s\n", err)
+		os.Exit(1)
+	}
+}
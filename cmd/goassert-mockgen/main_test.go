@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	for _, testcase := range []struct {
+		importPath, iface, mock, pkgName string
+		golden                           string
+	}{
+		{"", "Store", "MockStore", "store", "testdata/store_mock.golden"},
+		{"example.com/store", "Logger", "FakeLogger", "store_test", "testdata/logger_mock.golden"},
+	} {
+		pkg, err := loadPackage("testdata/store", testcase.importPath)
+		if err != nil {
+			t.Fatalf("test: cannot load package: %s", err)
+		}
+		src, err := generate(pkg, testcase.iface, testcase.mock, testcase.pkgName)
+		if err != nil {
+			t.Fatalf("test: cannot generate %s: %s", testcase.mock, err)
+		}
+		expected, err := os.ReadFile(testcase.golden)
+		if err != nil {
+			t.Fatalf("test: cannot read golden file: %s", err)
+		}
+		if string(expected) != string(src) {
+			t.Fatalf("test: expected %s, but got:\n%s", testcase.golden, src)
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	pkg, err := loadPackage("testdata/store", "")
+	if err != nil {
+		t.Fatalf("test: cannot load package: %s", err)
+	}
+	for _, testcase := range []struct {
+		iface, message string
+	}{
+		{"None", "None is not found in package store"},
+		{"Entry", "Entry is not an interface"},
+		{"Mocker", "method Mock of Mocker conflicts with the embedded goassert.Mock"},
+	} {
+		if _, err := generate(pkg, testcase.iface, "Mock", "store"); err == nil || err.Error() != testcase.message {
+			t.Fatalf("test: expected error %q, but got %v", testcase.message, err)
+		}
+	}
+	if _, err := loadPackage("testdata/none", ""); err == nil {
+		t.Fatalf("test: expected error, but got nil")
+	}
+}
@@ -0,0 +1,34 @@
+package store
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Entry is the entry of Store.
+type Entry[V any] struct {
+	Value   V
+	Expires time.Time
+}
+
+// Store is the key-value store.
+type Store[K comparable, V any] interface {
+	io.Closer
+	Get(ctx context.Context, key K) (Entry[V], error)
+	Put(context.Context, K, V, ...time.Duration) error
+	Keys(prefix string) []K
+	Reset()
+}
+
+// Logger is the logger.
+type Logger interface {
+	Init(arg1 string, _ int)
+	Logf(format string, v ...interface{})
+	Since(time time.Time) time.Duration
+}
+
+// Mocker is the interface which cannot be mocked.
+type Mocker interface {
+	Mock()
+}
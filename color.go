@@ -0,0 +1,85 @@
+package goassert
+
+import (
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// ColorEnv is the name of the environment variable controlling the colors of the failure messages.
+// The value "always" (or "1") enables the colors, and "never" (or "0") disables them.
+// Otherwise, the colors are enabled only if the standard output is a terminal, neither NO_COLOR nor CI is set, and TERM is not "dumb".
+const ColorEnv = "GOASSERT_COLOR"
+
+// The ANSI escape sequences used in the colored failure messages.
+const (
+	colorReset     = "\x1b[0m"
+	colorRed       = "\x1b[31m"
+	colorGreen     = "\x1b[32m"
+	colorReverse   = "\x1b[7m"
+	colorNoReverse = "\x1b[27m"
+)
+
+// colorFromEnv returns true if the colors are enabled by the environment.
+func colorFromEnv() bool {
+	switch strings.ToLower(os.Getenv(ColorEnv)) {
+	case "always", "1", "true":
+		return true
+	case "never", "0", "false":
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("CI") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorEnabled returns true if the failure messages reported to the testing context are colored.
+// HookedTestingTB colors its messages only if its field Color is true.
+func colorEnabled(tb TestingTB) bool {
+	switch tb := tb.(type) {
+	case *HookedTestingTB:
+		return tb.Color
+	case *Collect:
+		return tb.color
+	}
+	return colorFromEnv()
+}
+
+// colorOf returns the color of the line of the difference operation.
+func colorOf(kind byte) string {
+	if kind == '-' {
+		return colorRed
+	}
+	return colorGreen
+}
+
+// colorize returns the string wrapped in the color.
+func colorize(color, s string) string {
+	return color + s + colorReset
+}
+
+// highlightLine returns the colored line of the difference operation whose changed part from the other line is highlighted.
+func highlightLine(kind byte, line, other string) string {
+	prefix := 0
+	for prefix < len(line) && prefix < len(other) && line[prefix] == other[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(line)-prefix && suffix < len(other)-prefix && line[len(line)-1-suffix] == other[len(other)-1-suffix] {
+		suffix++
+	}
+	// Avoid splitting any UTF-8 sequence.
+	for prefix > 0 && prefix < len(line) && !utf8.RuneStart(line[prefix]) {
+		prefix--
+	}
+	for suffix > 0 && !utf8.RuneStart(line[len(line)-suffix]) {
+		suffix--
+	}
+	changed := line[prefix : len(line)-suffix]
+	if changed == "" {
+		return colorize(colorOf(kind), string(kind)+" "+line)
+	}
+	return colorize(colorOf(kind), string(kind)+" "+line[:prefix]+colorReverse+changed+colorNoReverse+line[len(line)-suffix:])
+}
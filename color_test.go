@@ -0,0 +1,100 @@
+package goassert
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestColorFromEnv(t *testing.T) {
+	for _, name := range []string{ColorEnv, "NO_COLOR", "CI", "TERM"} {
+		if value, ok := os.LookupEnv(name); ok {
+			defer os.Setenv(name, value)
+		} else {
+			defer os.Unsetenv(name)
+		}
+	}
+	for _, testcase := range []struct {
+		env      map[string]string
+		expected bool
+	}{
+		{map[string]string{ColorEnv: "always", "CI": "true"}, true},
+		{map[string]string{ColorEnv: "1", "NO_COLOR": "1"}, true},
+		{map[string]string{ColorEnv: "never"}, false},
+		{map[string]string{ColorEnv: "", "NO_COLOR": "1"}, false},
+		{map[string]string{ColorEnv: "", "CI": "true"}, false},
+		{map[string]string{ColorEnv: "", "TERM": "dumb"}, false},
+	} {
+		for _, name := range []string{ColorEnv, "NO_COLOR", "CI", "TERM"} {
+			os.Unsetenv(name)
+		}
+		for name, value := range testcase.env {
+			os.Setenv(name, value)
+		}
+		if got := colorFromEnv(); got != testcase.expected {
+			t.Fatalf("test: expected %v, but got %v with environment %v", testcase.expected, got, testcase.env)
+		}
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	// HookedTestingTB and its subtests and attempts should not be colored unless requested.
+	tb := NewHookedTestingTB("test")
+	if colorEnabled(tb) {
+		t.Fatalf("test: unexpected colorEnabled(tb) == true")
+	}
+	tb.Color = true
+	tb.Run("sub", func(sub *HookedTestingTB) {
+		if !colorEnabled(sub) {
+			t.Fatalf("test: unexpected colorEnabled(sub) == false")
+		}
+	})
+	New(tb).EventuallyWith(func(c *Collect) {
+		if !colorEnabled(c) {
+			t.Fatalf("test: unexpected colorEnabled(c) == false")
+		}
+	}, time.Second, time.Millisecond)
+}
+
+func TestDiffLinesColor(t *testing.T) {
+	for _, testcase := range []struct {
+		expected, actual string
+		diff             string
+	}{
+		{"a\nhello world\nb", "a\nhello w0rld\nb", "  a\n\x1b[31m- hello w\x1b[7mo\x1b[27mrld\x1b[0m\n\x1b[32m+ hello w\x1b[7m0\x1b[27mrld\x1b[0m\n  b"},
+		{"a\nb", "a\nc\nd", "  a\n\x1b[31m- b\x1b[0m\n\x1b[32m+ c\x1b[0m\n\x1b[32m+ d\x1b[0m"},
+		{"héllo", "hèllo", "\x1b[31m- h\x1b[7mé\x1b[27mllo\x1b[0m\n\x1b[32m+ h\x1b[7mè\x1b[27mllo\x1b[0m"},
+	} {
+		if got := diffLines(testcase.expected, testcase.actual, true); got != testcase.diff {
+			t.Fatalf("test: expected %q, but got %q as difference between %q and %q", testcase.diff, got, testcase.expected, testcase.actual)
+		}
+	}
+}
+
+func TestAssertEqualDiff(t *testing.T) {
+	type config struct {
+		Name    string
+		Servers []string
+	}
+	expected := config{Name: "production", Servers: []string{"alpha.example.com", "beta.example.com"}}
+	actual := config{Name: "production", Servers: []string{"alpha.example.com", "gamma.example.com"}}
+	// test1: plain difference
+	tb1 := NewHookedTestingTB("test1")
+	New(tb1, expected).Equal(actual)
+	if !reflect.DeepEqual(tb1.Messages, []string{
+		"ERROR: at #0 value, expected goassert.config{\n  Name: \"production\",\n  Servers: []string{\"alpha.example.com\", \"beta.example.com\"},\n} (goassert.config), " +
+			"but got goassert.config{\n  Name: \"production\",\n  Servers: []string{\"alpha.example.com\", \"gamma.example.com\"},\n} (goassert.config)\n" +
+			"diff (-expected +actual):\n  goassert.config{\n    Name: \"production\",\n-   Servers: []string{\"alpha.example.com\", \"beta.example.com\"},\n+   Servers: []string{\"alpha.example.com\", \"gamma.example.com\"},\n  } (goassert.config)",
+	}) {
+		t.Fatalf("test1: unexpected Messages: %#v", tb1.Messages)
+	}
+	// test2: colored difference
+	tb2 := NewHookedTestingTB("test2")
+	tb2.Color = true
+	New(tb2, expected).Equal(actual)
+	if !(len(tb2.Messages) == 1 && strings.HasSuffix(tb2.Messages[0], "\n\x1b[32m+   Servers: []string{\"alpha.example.com\", \"\x1b[7mgamm\x1b[27ma.example.com\"},\x1b[0m\n  } (goassert.config)")) {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+}
@@ -0,0 +1,411 @@
+package goassert
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// reference is the reference to the shared value, which is a pointer, a map or a non-empty slice.
+// The length is needed to distinguish the slices sharing the same underlying array.
+type reference struct {
+	ptr uintptr
+	len int
+	typ reflect.Type
+}
+
+// referenceOf returns the reference of the value.
+func referenceOf(v reflect.Value) reference {
+	ref := reference{ptr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		ref.len = v.Len()
+	}
+	return ref
+}
+
+// CompareOption is the option of the comparisons in Assert.
+type CompareOption func(options *compareOptions)
+
+// compareOptions is the set of the options of the comparisons.
+type compareOptions struct {
+	// allowUnexported and ignoreUnexported are the sets of the struct types whose unexported fields are compared or ignored explicitly.
+	// The nil key means all the struct types.
+	allowUnexported, ignoreUnexported map[reflect.Type]bool
+}
+
+// structTypesOf returns the struct types of the values, which may be the pointers to the structs.
+// If no value is given, structTypesOf returns only the nil type meaning all the struct types.
+func structTypesOf(values []interface{}) []reflect.Type {
+	if len(values) == 0 {
+		return []reflect.Type{nil}
+	}
+	types := make([]reflect.Type, len(values))
+	for i, v := range values {
+		t := reflect.TypeOf(v)
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		types[i] = t
+	}
+	return types
+}
+
+// AllowUnexported returns the option comparing the unexported fields of the struct types of the values explicitly.
+// If no value is given, the option applies to all the struct types.
+// The unexported fields are compared by default, but the mismatch in them is noted in the failure message of Assert.Equal unless they are allowed explicitly.
+func AllowUnexported(values ...interface{}) CompareOption {
+	types := structTypesOf(values)
+	return func(options *compareOptions) {
+		for _, t := range types {
+			options.allowUnexported[t] = true
+		}
+	}
+}
+
+// IgnoreUnexported returns the option ignoring the unexported fields of the struct types of the values.
+// If no value is given, the option applies to all the struct types.
+// IgnoreUnexported takes precedence over AllowUnexported.
+func IgnoreUnexported(values ...interface{}) CompareOption {
+	types := structTypesOf(values)
+	return func(options *compareOptions) {
+		for _, t := range types {
+			options.ignoreUnexported[t] = true
+		}
+	}
+}
+
+// allows returns true if the unexported fields of the struct type are compared explicitly.
+func (options *compareOptions) allows(t reflect.Type) bool {
+	return options.allowUnexported[nil] || options.allowUnexported[t]
+}
+
+// ignores returns true if the unexported fields of the struct type are ignored.
+func (options *compareOptions) ignores(t reflect.Type) bool {
+	return options.ignoreUnexported[nil] || options.ignoreUnexported[t]
+}
+
+// comparer compares the values as reflect.DeepEqual does, but the cyclic graphs must be isomorphic.
+// The back-references in the expected value must refer to the ancestors corresponding to the ones referred by the back-references in the actual value.
+type comparer struct {
+	options compareOptions
+	// forward and backward are the correspondences between the references being compared in the expected and actual values.
+	forward, backward map[reference]correspondence
+	// depth is the number of the references being compared, and low is the minimum depth of the ancestors referred by the back-references in the values being compared.
+	depth, low int
+	// equalPairs is the set of the pairs of the references found equal regardless of their ancestors.
+	equalPairs map[[2]reference]bool
+	// path is the path of the values being compared from the root values, which is formatted only at the mismatch.
+	path []pathSegment
+	// unexportedPath is the length of the path to the outermost unexported field which is not allowed explicitly, or zero if there is no such field.
+	// unexportedType is the struct type of the field.
+	unexportedPath int
+	unexportedType reflect.Type
+	// mismatch is the path of the first found mismatch, and mismatchExpected and mismatchActual are the values there.
+	// mismatchUnexported and mismatchUnexportedType are unexportedPath and unexportedType there.
+	mismatch               string
+	mismatchFound          bool
+	mismatchExpected       reflect.Value
+	mismatchActual         reflect.Value
+	mismatchUnexported     string
+	mismatchUnexportedType reflect.Type
+}
+
+// newComparer returns a new comparer with the options.
+func newComparer(options []CompareOption) *comparer {
+	c := &comparer{
+		options: compareOptions{
+			allowUnexported:  map[reflect.Type]bool{},
+			ignoreUnexported: map[reflect.Type]bool{},
+		},
+		forward:    map[reference]correspondence{},
+		backward:   map[reference]correspondence{},
+		equalPairs: map[[2]reference]bool{},
+	}
+	for _, option := range options {
+		option(&c.options)
+	}
+	return c
+}
+
+// deepEqual returns true if the expected value deeply equals the actual value.
+// Unlike reflect.DeepEqual, the cyclic graphs of the different shapes are not equal, e.g. the list linking to itself and the two-node cyclic list.
+func deepEqual(expected, actual interface{}) bool {
+	return newComparer(nil).equal(reflect.ValueOf(expected), reflect.ValueOf(actual))
+}
+
+// notes returns the notes explaining the first found mismatch.
+func (c *comparer) notes() []string {
+	notes := []string{}
+	if note := c.typeNote(); note != "" {
+		if c.mismatch != "" {
+			note = fmt.Sprintf("at %s, %s", c.mismatch, note)
+		}
+		notes = append(notes, "note: "+note)
+	}
+	if c.mismatchUnexported != "" {
+		notes = append(notes, fmt.Sprintf("note: the values differ in unexported field %s of %s, which is compared by default; use AllowUnexported or IgnoreUnexported to choose explicitly", c.mismatchUnexported, c.mismatchUnexportedType))
+	}
+	return notes
+}
+
+// unwrapInterface returns the value in the interface value, or the invalid value if the interface value is nil.
+func unwrapInterface(v reflect.Value) reflect.Value {
+	if v.IsValid() && v.Kind() == reflect.Interface {
+		return v.Elem()
+	}
+	return v
+}
+
+// isNilValue returns true if the value is the nil pointer, map, slice, channel or function.
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func:
+		return v.IsNil()
+	}
+	return false
+}
+
+// isUnderlyingOf returns true if type u is the underlying type of the different named type t.
+// The predeclared types such as int are regarded as the underlying types.
+func isUnderlyingOf(u, t reflect.Type) bool {
+	if u == t || u.Kind() != t.Kind() || t.Name() == "" || t.PkgPath() == "" || !t.ConvertibleTo(u) {
+		return false
+	}
+	return u.Name() == "" || (u.PkgPath() == "" && u.Name() == u.Kind().String())
+}
+
+// typeNote returns the explanation of the mismatch caused by the types, or the empty string if the mismatch is not caused by the types.
+func (c *comparer) typeNote() string {
+	x, y := unwrapInterface(c.mismatchExpected), unwrapInterface(c.mismatchActual)
+	switch {
+	case !x.IsValid() && !y.IsValid():
+		return ""
+	case !x.IsValid():
+		if isNilValue(y) {
+			return fmt.Sprintf("the actual value is nil %s in the non-nil interface, which does not equal the nil interface", y.Type())
+		}
+		return ""
+	case !y.IsValid():
+		if isNilValue(x) {
+			return fmt.Sprintf("the expected value is nil %s in the non-nil interface, which does not equal the nil interface", x.Type())
+		}
+		return ""
+	}
+	tx, ty := x.Type(), y.Type()
+	switch {
+	case tx == ty:
+		return ""
+	case tx.Kind() == reflect.Ptr && tx.Elem() == ty:
+		return fmt.Sprintf("the expected value is pointer %s, but the actual value is not a pointer", tx)
+	case ty.Kind() == reflect.Ptr && ty.Elem() == tx:
+		return fmt.Sprintf("the actual value is pointer %s, but the expected value is not a pointer", ty)
+	case isUnderlyingOf(ty, tx):
+		return fmt.Sprintf("the actual type %s is the underlying type of the expected type %s", ty, tx)
+	case isUnderlyingOf(tx, ty):
+		return fmt.Sprintf("the expected type %s is the underlying type of the actual type %s", tx, ty)
+	}
+	limits := DefaultFormatConfig.limits()
+	if newPrettyPrinter(limits).print(x, false) == newPrettyPrinter(limits).print(y, false) {
+		return fmt.Sprintf("the values look the same, but have the different types %s and %s", tx, ty)
+	}
+	return ""
+}
+
+// pathSegment is the segment of the path, which is the field name, the index or the map key.
+type pathSegment struct {
+	field string
+	index int
+	key   reflect.Value
+}
+
+// formatPath returns the formatted path such as ".Children[0].Name".
+func formatPath(path []pathSegment) string {
+	s := ""
+	for _, segment := range path {
+		switch {
+		case segment.field != "":
+			s += "." + segment.field
+		case segment.key.IsValid():
+			s += "[" + newPrettyPrinter(DefaultFormatConfig.limits()).print(segment.key, false) + "]"
+		default:
+			s += fmt.Sprintf("[%d]", segment.index)
+		}
+	}
+	return s
+}
+
+// equalAt returns true if the nested values at the path segment are deeply equal.
+func (c *comparer) equalAt(segment pathSegment, x, y reflect.Value) bool {
+	c.path = append(c.path, segment)
+	equal := c.equal(x, y)
+	c.path = c.path[:len(c.path)-1]
+	return equal
+}
+
+// equal returns true if the values are deeply equal.
+// The first found mismatch is recorded with its path.
+func (c *comparer) equal(x, y reflect.Value) bool {
+	if c.equalValues(x, y) {
+		return true
+	}
+	if !c.mismatchFound {
+		c.mismatch, c.mismatchFound = formatPath(c.path), true
+		c.mismatchExpected, c.mismatchActual = x, y
+		if c.unexportedPath > 0 {
+			c.mismatchUnexported, c.mismatchUnexportedType = formatPath(c.path[:c.unexportedPath]), c.unexportedType
+		}
+	}
+	return false
+}
+
+// correspondence is the reference corresponding to the reference being compared at the depth.
+type correspondence struct {
+	ref   reference
+	depth int
+}
+
+// enter marks the references as being compared, and returns true.
+// If either reference is already being compared, which means the back-reference, enter returns false with whether they correspond each other or not.
+func (c *comparer) enter(rx, ry reference) (bool, bool) {
+	fx, okx := c.forward[rx]
+	by, oky := c.backward[ry]
+	if okx || oky {
+		if okx && fx.depth < c.low {
+			c.low = fx.depth
+		}
+		if oky && by.depth < c.low {
+			c.low = by.depth
+		}
+		return false, okx && oky && fx.ref == ry && by.ref == rx
+	}
+	c.forward[rx], c.backward[ry] = correspondence{ry, c.depth}, correspondence{rx, c.depth}
+	c.depth++
+	return true, true
+}
+
+// leave unmarks the references compared with the result.
+// The equal pair is remembered if its back-references refer only to itself or its descendants, because the result does not depend on its ancestors.
+func (c *comparer) leave(rx, ry reference, equal bool) {
+	c.depth--
+	delete(c.forward, rx)
+	delete(c.backward, ry)
+	if equal && c.low >= c.depth {
+		c.equalPairs[[2]reference{rx, ry}] = true
+	}
+}
+
+// equalReferences returns true if the values of the references are deeply equal.
+// The identical references are equal as reflect.DeepEqual does, and the pairs found equal already are not compared again.
+func (c *comparer) equalReferences(x, y reflect.Value) bool {
+	rx, ry := referenceOf(x), referenceOf(y)
+	if rx == ry || c.equalPairs[[2]reference{rx, ry}] {
+		return true
+	}
+	entered, ok := c.enter(rx, ry)
+	if !entered {
+		return ok
+	}
+	low := c.low
+	c.low = c.depth
+	equal := c.equalElements(x, y)
+	c.leave(rx, ry, equal)
+	if c.low > low {
+		c.low = low
+	}
+	return equal
+}
+
+// equalValues returns true if the values are deeply equal.
+func (c *comparer) equalValues(x, y reflect.Value) bool {
+	if !x.IsValid() || !y.IsValid() {
+		return x.IsValid() == y.IsValid()
+	}
+	if x.Type() != y.Type() {
+		return false
+	}
+	switch x.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if x.IsNil() || y.IsNil() {
+			return x.IsNil() == y.IsNil()
+		}
+		if x.Kind() == reflect.Slice && x.Len() != y.Len() {
+			return false
+		}
+		if x.Kind() != reflect.Slice || x.Len() > 0 {
+			return c.equalReferences(x, y)
+		}
+	}
+	return c.equalElements(x, y)
+}
+
+// equalElements returns true if the values of the same type are deeply equal, comparing their elements.
+func (c *comparer) equalElements(x, y reflect.Value) bool {
+	switch x.Kind() {
+	case reflect.Bool:
+		return x.Bool() == y.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return x.Int() == y.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return x.Uint() == y.Uint()
+	case reflect.Float32, reflect.Float64:
+		return x.Float() == y.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return x.Complex() == y.Complex()
+	case reflect.String:
+		return x.String() == y.String()
+	case reflect.Interface:
+		if x.IsNil() || y.IsNil() {
+			return x.IsNil() == y.IsNil()
+		}
+		return c.equal(x.Elem(), y.Elem())
+	case reflect.Ptr:
+		return c.equal(x.Elem(), y.Elem())
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < x.Len(); i++ {
+			if !c.equalAt(pathSegment{index: i}, x.Index(i), y.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if x.Len() != y.Len() {
+			return false
+		}
+		iter := x.MapRange()
+		for iter.Next() {
+			yv := y.MapIndex(iter.Key())
+			if !yv.IsValid() || !c.equalAt(pathSegment{key: iter.Key()}, iter.Value(), yv) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < x.NumField(); i++ {
+			if !c.equalField(x, y, i) {
+				return false
+			}
+		}
+		return true
+	case reflect.Func:
+		return x.IsNil() && y.IsNil()
+	case reflect.Chan, reflect.UnsafePointer:
+		return x.Pointer() == y.Pointer()
+	}
+	return false
+}
+
+// equalField returns true if the i-th fields of the structs are deeply equal or ignored.
+func (c *comparer) equalField(x, y reflect.Value, i int) bool {
+	field := x.Type().Field(i)
+	if !field.IsExported() {
+		if c.options.ignores(x.Type()) {
+			return true
+		}
+		if c.unexportedPath == 0 && !c.options.allows(x.Type()) {
+			c.unexportedPath, c.unexportedType = len(c.path)+1, x.Type()
+			defer func() {
+				c.unexportedPath, c.unexportedType = 0, nil
+			}()
+		}
+	}
+	return c.equalAt(pathSegment{field: field.Name}, x.Field(i), y.Field(i))
+}
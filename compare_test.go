@@ -0,0 +1,256 @@
+package goassert
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeepEqual(t *testing.T) {
+	type node struct {
+		Value int
+		Next  *node
+	}
+	type tree struct {
+		Name     string
+		Parent   *tree
+		Children []*tree
+	}
+	// ring returns the cyclic list of n nodes with the value.
+	ring := func(n, value int) *node {
+		head := &node{Value: value}
+		last := head
+		for i := 1; i < n; i++ {
+			last.Next = &node{Value: value}
+			last = last.Next
+		}
+		last.Next = head
+		return head
+	}
+	// family returns the tree whose children refer to the parent.
+	family := func(names ...string) *tree {
+		root := &tree{Name: "root"}
+		for _, name := range names {
+			root.Children = append(root.Children, &tree{Name: name, Parent: root})
+		}
+		return root
+	}
+	shared := &node{Value: 1}
+	cyclicSlice := []interface{}{1, nil}
+	cyclicSlice[1] = cyclicSlice
+	cyclicMap := map[string]interface{}{}
+	cyclicMap["self"] = cyclicMap
+	for i, testcase := range []struct {
+		x, y  interface{}
+		equal bool
+	}{
+		{nil, nil, true},
+		{1, 1, true},
+		{1, int64(1), false},
+		{math.NaN(), math.NaN(), false},
+		{[]int{}, []int(nil), false},
+		{[]int{1, 2}, []int{1, 2}, true},
+		{map[string]int{"a": 1}, map[string]int{"a": 1}, true},
+		{map[string]int{"a": 1}, map[string]int{"b": 1}, false},
+		{func() {}, func() {}, false},
+		{(func())(nil), (func())(nil), true},
+		{[]*node{shared, shared}, []*node{{Value: 1}, {Value: 1}}, true},
+		{ring(1, 1), ring(1, 1), true},
+		{ring(2, 1), ring(2, 1), true},
+		{ring(1, 1), ring(2, 1), false},
+		{ring(2, 1), ring(3, 1), false},
+		{ring(2, 1), ring(2, 2), false},
+		{family("a", "b"), family("a", "b"), true},
+		{family("a", "b"), family("a", "c"), false},
+		{cyclicSlice, cyclicSlice, true},
+		{cyclicMap, cyclicMap, true},
+	} {
+		if got := deepEqual(testcase.x, testcase.y); got != testcase.equal {
+			t.Fatalf("test #%d: expected %v, but got %v", i, testcase.equal, got)
+		}
+		if testcase.equal && !reflect.DeepEqual(testcase.x, testcase.y) {
+			t.Fatalf("test #%d: unexpected reflect.DeepEqual == false", i)
+		}
+	}
+}
+
+func TestDeepEqualShared(t *testing.T) {
+	type node struct {
+		L, R *node
+		V    int
+	}
+	// dag returns the DAG of the depth whose nodes refer to the same child twice.
+	dag := func(depth, leaf int) *node {
+		n := &node{V: leaf}
+		for i := 0; i < depth; i++ {
+			n = &node{L: n, R: n, V: i}
+		}
+		return n
+	}
+	// test1: The shared references are compared once.
+	done := make(chan bool, 1)
+	go func() {
+		done <- deepEqual(dag(26, 0), dag(26, 0)) && !deepEqual(dag(26, 0), dag(26, 1))
+	}()
+	select {
+	case equal := <-done:
+		if !equal {
+			t.Fatalf("test1: unexpected deepEqual results")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("test1: deepEqual of the DAGs does not finish")
+	}
+	// test2: The identical references are equal even if they hold NaN.
+	m := map[string]float64{"nan": math.NaN()}
+	s := []float64{math.NaN()}
+	p := &s
+	if !(deepEqual(m, m) && deepEqual(s, s) && deepEqual(p, p)) {
+		t.Fatalf("test2: unexpected deepEqual == false")
+	}
+	if deepEqual(map[string]float64{"nan": math.NaN()}, m) {
+		t.Fatalf("test2: unexpected deepEqual == true")
+	}
+	tb := NewHookedTestingTB("test2")
+	New(tb, m).Equal(m)
+	if len(tb.Messages) != 0 {
+		t.Fatalf("test2: unexpected Messages: %#v", tb.Messages)
+	}
+}
+
+func TestAssertEqualCyclic(t *testing.T) {
+	type tree struct {
+		Name     string
+		Parent   *tree
+		Children []*tree
+	}
+	// newTree returns the tree whose grandchild has the name.
+	newTree := func(name string) *tree {
+		root := &tree{Name: "root"}
+		child := &tree{Name: "child", Parent: root}
+		child.Children = []*tree{{Name: name, Parent: child}}
+		root.Children = []*tree{child}
+		return root
+	}
+	tb := NewHookedTestingTB("test")
+	New(tb, newTree("a")).Equal(newTree("b"))
+	if !(len(tb.Messages) == 1 && strings.HasSuffix(tb.Messages[0], "\ndiff (-expected +actual):\n"+
+		"  &goassert.tree{\n"+
+		"    Name: \"root\",\n"+
+		"    Parent: (*goassert.tree)(nil),\n"+
+		"    Children: []*goassert.tree{\n"+
+		"      &{\n"+
+		"        Name: \"child\",\n"+
+		"        Parent: <cycle to root>,\n"+
+		"        Children: []*goassert.tree{\n"+
+		"          &{\n"+
+		"-           Name: \"a\",\n"+
+		"+           Name: \"b\",\n"+
+		"            Parent: <cycle to .Children[0]>,\n"+
+		"            Children: []*goassert.tree(nil),\n"+
+		"          },\n"+
+		"        },\n"+
+		"      },\n"+
+		"    },\n"+
+		"  } (*goassert.tree)")) {
+		t.Fatalf("test: unexpected Messages: %#v", tb.Messages)
+	}
+}
+
+func TestAssertEqualUnexported(t *testing.T) {
+	type cache struct {
+		Key  string
+		hits int
+	}
+	type entry struct {
+		Name  string
+		cache cache
+	}
+	// test1: The mismatch in the unexported field is noted by default.
+	tb1 := NewHookedTestingTB("test1")
+	New(tb1, entry{Name: "a", cache: cache{Key: "k", hits: 1}}).Equal(entry{Name: "a", cache: cache{Key: "k", hits: 2}})
+	if !(len(tb1.Messages) == 1 && strings.HasSuffix(tb1.Messages[0], "\nnote: the values differ in unexported field .cache of goassert.entry, which is compared by default; use AllowUnexported or IgnoreUnexported to choose explicitly")) {
+		t.Fatalf("test1: unexpected Messages: %#v", tb1.Messages)
+	}
+	// test2: The mismatch in the exported field is not noted.
+	tb2 := NewHookedTestingTB("test2")
+	New(tb2, entry{Name: "a"}).Equal(entry{Name: "b"})
+	if !(len(tb2.Messages) == 1 && !strings.Contains(tb2.Messages[0], "note:")) {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	// test3: AllowUnexported compares the unexported fields without the note.
+	tb3 := NewHookedTestingTB("test3")
+	New(tb3, entry{cache: cache{hits: 1}}).WithOptions(AllowUnexported(entry{}, cache{})).Equal(entry{cache: cache{hits: 2}})
+	if !(len(tb3.Messages) == 1 && !strings.Contains(tb3.Messages[0], "note:")) {
+		t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+	}
+	// test4: AllowUnexported of the other type does not suppress the note at the innermost unexported field.
+	tb4 := NewHookedTestingTB("test4")
+	New(tb4, entry{cache: cache{hits: 1}}).WithOptions(AllowUnexported(&entry{})).Equal(entry{cache: cache{hits: 2}})
+	if !(len(tb4.Messages) == 1 && strings.HasSuffix(tb4.Messages[0], "\nnote: the values differ in unexported field .cache.hits of goassert.cache, which is compared by default; use AllowUnexported or IgnoreUnexported to choose explicitly")) {
+		t.Fatalf("test4: unexpected Messages: %#v", tb4.Messages)
+	}
+	// test5: IgnoreUnexported ignores the unexported fields of the given types.
+	tb5 := NewHookedTestingTB("test5")
+	New(tb5, entry{cache: cache{Key: "k", hits: 1}}).WithOptions(IgnoreUnexported(cache{})).Equal(entry{cache: cache{Key: "k", hits: 2}})
+	New(tb5, entry{cache: cache{Key: "k"}}).WithOptions(IgnoreUnexported()).Equal(entry{cache: cache{Key: "l"}})
+	if len(tb5.Messages) != 0 {
+		t.Fatalf("test5: unexpected Messages: %#v", tb5.Messages)
+	}
+	// test6: IgnoreUnexported takes precedence over AllowUnexported, and the options are inherited by New.
+	tb6 := NewHookedTestingTB("test6")
+	New(tb6).WithOptions(AllowUnexported(), IgnoreUnexported(entry{})).New(entry{cache: cache{hits: 1}}).Equal(entry{cache: cache{hits: 2}})
+	if len(tb6.Messages) != 0 {
+		t.Fatalf("test6: unexpected Messages: %#v", tb6.Messages)
+	}
+	// test7: The options are used by ExpectPanic.
+	tb7 := NewHookedTestingTB("test7")
+	New(tb7, cache{Key: "k", hits: 1}).WithOptions(IgnoreUnexported()).ExpectPanic(func() {
+		panic(cache{Key: "k", hits: 2})
+	})
+	if len(tb7.Messages) != 0 {
+		t.Fatalf("test7: unexpected Messages: %#v", tb7.Messages)
+	}
+}
+
+func TestAssertEqualTypeNotes(t *testing.T) {
+	type id int
+	type point struct {
+		X, Y int
+	}
+	type wrapper struct {
+		Value interface{}
+	}
+	var nilPoint *point
+	var nilError error
+	for i, testcase := range []struct {
+		expected, actual interface{}
+		note             string
+	}{
+		{1, int64(1), "note: the values look the same, but have the different types int and int64"},
+		{[]interface{}{1.0}, []interface{}{float32(1.0)}, "note: at [0], the values look the same, but have the different types float64 and float32"},
+		{map[string]interface{}{"a": 1}, map[string]interface{}{"a": int64(1)}, "note: at [\"a\"], the values look the same, but have the different types int and int64"},
+		{nilError, nilPoint, "note: the actual value is nil *goassert.point in the non-nil interface, which does not equal the nil interface"},
+		{wrapper{nilPoint}, wrapper{}, "note: at .Value, the expected value is nil *goassert.point in the non-nil interface, which does not equal the nil interface"},
+		{&point{1, 2}, point{1, 2}, "note: the expected value is pointer *goassert.point, but the actual value is not a pointer"},
+		{point{1, 2}, &point{1, 2}, "note: the actual value is pointer *goassert.point, but the expected value is not a pointer"},
+		{id(1), 1, "note: the actual type int is the underlying type of the expected type goassert.id"},
+		{1, id(1), "note: the expected type int is the underlying type of the actual type goassert.id"},
+		{1, 2, ""},
+		{1, "1", ""},
+	} {
+		tb := NewHookedTestingTB("test")
+		New(tb, testcase.expected).Equal(testcase.actual)
+		if len(tb.Messages) != 1 {
+			t.Fatalf("test%d: unexpected Messages: %#v", i+1, tb.Messages)
+		}
+		note := ""
+		if j := strings.LastIndex(tb.Messages[0], "\nnote: "); j >= 0 {
+			note = tb.Messages[0][j+1:]
+		}
+		if note != testcase.note {
+			t.Fatalf("test%d: unexpected note: %q", i+1, note)
+		}
+	}
+}
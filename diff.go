@@ -0,0 +1,121 @@
+package goassert
+
+import (
+	"strings"
+)
+
+// diffOp is the operation of the line-by-line difference.
+type diffOp struct {
+	// kind is '-' for the removed line, '+' for the added line, and ' ' for the common line.
+	kind byte
+	line string
+}
+
+// maxDiffCells is the max number of the cells of the LCS table computed by diffOps.
+const maxDiffCells = 1 << 20
+
+// diffOps returns the operations transforming the expected lines into the actual lines.
+// The common leading and trailing lines are matched first.
+// If the LCS table of the rest is larger than maxDiffCells, the rest is regarded as removed and added entirely instead of the minimal difference.
+func diffOps(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	ops := []diffOp{}
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+	} else {
+		ops = append(ops, lcsDiffOps(a, b)...)
+	}
+	for _, line := range common {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// lcsDiffOps returns the minimal operations transforming the expected lines into the actual lines with the LCS table.
+func lcsDiffOps(a, b []string) []diffOp {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	ops := []diffOp{}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i, j = i+1, j+1
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	return ops
+}
+
+// diffLines returns the line-by-line difference between the expected and actual strings.
+// The removed lines are prefixed by "- ", the added lines by "+ ", and the common lines by "  ".
+// If color is true, the removed and added lines are colored, and their changed parts are highlighted.
+func diffLines(expected, actual string, color bool) string {
+	ops := diffOps(strings.Split(expected, "\n"), strings.Split(actual, "\n"))
+	lines := []string{}
+	for i := 0; i < len(ops); {
+		if !color || ops[i].kind == ' ' {
+			lines = append(lines, string(ops[i].kind)+" "+ops[i].line)
+			i++
+			continue
+		}
+		// The run of the removed lines followed by the same number of the added lines is highlighted pairwise.
+		removed, added := i, i
+		for removed < len(ops) && ops[removed].kind == '-' {
+			removed++
+		}
+		for added = removed; added < len(ops) && ops[added].kind == '+'; added++ {
+		}
+		if n := removed - i; n > 0 && added-removed == n {
+			for k := 0; k < n; k++ {
+				lines = append(lines, highlightLine('-', ops[i+k].line, ops[removed+k].line))
+			}
+			for k := 0; k < n; k++ {
+				lines = append(lines, highlightLine('+', ops[removed+k].line, ops[i+k].line))
+			}
+		} else {
+			for k := i; k < added; k++ {
+				lines = append(lines, colorize(colorOf(ops[k].kind), string(ops[k].kind)+" "+ops[k].line))
+			}
+		}
+		i = added
+	}
+	return strings.Join(lines, "\n")
+}
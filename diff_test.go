@@ -0,0 +1,39 @@
+package goassert
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	for _, testcase := range []struct {
+		expected, actual string
+		diff             string
+	}{
+		{"hello", "hello", "  hello"},
+		{"hello\nworld", "hello\nw0rld", "  hello\n- world\n+ w0rld"},
+		{"a\nb\nc", "a\nc\nd", "  a\n- b\n  c\n+ d"},
+		{"", "a", "- \n+ a"},
+		{"a\nb\nc\nd", "a\nd", "  a\n- b\n- c\n  d"},
+	} {
+		if got := diffLines(testcase.expected, testcase.actual, false); got != testcase.diff {
+			t.Fatalf("test: expected %q, but got %q as difference between %q and %q", testcase.diff, got, testcase.expected, testcase.actual)
+		}
+	}
+}
+
+func TestDiffOpsLarge(t *testing.T) {
+	// test1: large differences fall back to removing and adding the changed lines entirely
+	a, b := []string{"head"}, []string{"head"}
+	for i := 0; i < 2000; i++ {
+		a, b = append(a, fmt.Sprintf("a%d", i)), append(b, fmt.Sprintf("b%d", i%1000))
+	}
+	a, b = append(a, "tail"), append(b, "tail")
+	ops := diffOps(a, b)
+	if len(ops) != 4002 {
+		t.Fatalf("test1: unexpected number of operations: %d", len(ops))
+	}
+	if !(ops[0] == diffOp{' ', "head"} && ops[1] == diffOp{'-', "a0"} && ops[2001] == diffOp{'+', "b0"} && ops[4001] == diffOp{' ', "tail"}) {
+		t.Fatalf("test1: unexpected operations: %v, %v, %v, %v", ops[0], ops[1], ops[2001], ops[4001])
+	}
+}
@@ -0,0 +1,193 @@
+package goassert
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Collect implements TestingTB.
+// This collects the failures of one attempt in EventuallyWith and ConsistentlyWith.
+// FailNow, Fatal and Fatalf abort only the current attempt.
+type Collect struct {
+	// name is the name of the parent TestingTB for method Name.
+	name string
+	// messages is the slice of the logged messages in the current attempt.
+	messages []string
+	// failed indicates whether the current attempt has failed already or not.
+	failed bool
+	// cleanups is the slice of the cleanup functions registered in the current attempt.
+	cleanups []func()
+	// color indicates whether the failure messages are colored as the parent TestingTB or not.
+	color bool
+}
+
+// collectAbort is the object passed to panic when Collect.FailNow is called.
+type collectAbort struct{}
+
+// Cleanup is for interface TestingTB.
+// The registered functions are called at the end of the current attempt.
+func (c *Collect) Cleanup(f func()) {
+	c.cleanups = append(c.cleanups, f)
+}
+
+// Error is for interface TestingTB.
+func (c *Collect) Error(args ...interface{}) {
+	c.Log(append([]interface{}{"ERROR: "}, args...)...)
+	c.Fail()
+}
+
+// Errorf is for interface TestingTB.
+func (c *Collect) Errorf(format string, args ...interface{}) {
+	c.Logf("ERROR: "+format, args...)
+	c.Fail()
+}
+
+// Fail is for interface TestingTB.
+func (c *Collect) Fail() {
+	c.failed = true
+}
+
+// FailNow is for interface TestingTB.
+func (c *Collect) FailNow() {
+	c.Fail()
+	panic(collectAbort{})
+}
+
+// Failed is for interface TestingTB.
+func (c *Collect) Failed() bool {
+	return c.failed
+}
+
+// Fatal is for interface TestingTB.
+func (c *Collect) Fatal(args ...interface{}) {
+	c.Log(append([]interface{}{"FATAL: "}, args...)...)
+	c.FailNow()
+}
+
+// Fatalf is for interface TestingTB.
+func (c *Collect) Fatalf(format string, args ...interface{}) {
+	c.Logf("FATAL: "+format, args...)
+	c.FailNow()
+}
+
+// Helper is for interface TestingTB.
+func (c *Collect) Helper() {
+}
+
+// Log is for interface TestingTB.
+func (c *Collect) Log(args ...interface{}) {
+	c.messages = append(c.messages, fmt.Sprint(args...))
+}
+
+// Logf is for interface TestingTB.
+func (c *Collect) Logf(format string, args ...interface{}) {
+	c.messages = append(c.messages, fmt.Sprintf(format, args...))
+}
+
+// Name is for interface TestingTB.
+func (c *Collect) Name() string {
+	return c.name
+}
+
+// run calls the callback as one attempt, and returns true if the attempt succeeds.
+func (c *Collect) run(callback func(c *Collect)) bool {
+	c.messages, c.failed = []string{}, false
+	func() {
+		defer func() {
+			for len(c.cleanups) > 0 {
+				f := c.cleanups[len(c.cleanups)-1]
+				c.cleanups = c.cleanups[:len(c.cleanups)-1]
+				f()
+			}
+		}()
+		defer func() {
+			if v := recover(); v != nil {
+				if _, ok := v.(collectAbort); !ok {
+					panic(v)
+				}
+			}
+		}()
+		callback(c)
+	}()
+	return !c.failed
+}
+
+// poll calls the attempt every interval until the attempt returns the wanted value or the timeout elapses.
+// poll returns the number of the attempts and whether the wanted value is returned or not.
+func poll(timeout, interval time.Duration, want bool, attempt func() bool) (int, bool) {
+	deadline := time.Now().Add(timeout)
+	for n := 1; ; n++ {
+		if attempt() == want {
+			return n, true
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return n, false
+		}
+		if remaining < interval {
+			time.Sleep(remaining)
+		} else {
+			time.Sleep(interval)
+		}
+	}
+}
+
+// checkPolling checks that the timeout and interval are valid.
+func (assert *Assert) checkPolling(timeout, interval time.Duration) {
+	assert.tb.Helper()
+	if timeout < 0 {
+		assert.tb.Fatalf("timeout must be non-negative, but got %s", timeout)
+	}
+	if interval <= 0 {
+		assert.tb.Fatalf("interval must be positive, but got %s", interval)
+	}
+}
+
+// Eventually checks that the condition is satisfied within the timeout.
+// The condition is checked every interval.
+func (assert *Assert) Eventually(cond func() bool, timeout, interval time.Duration) {
+	assert.tb.Helper()
+	assert.checkPolling(timeout, interval)
+	if _, ok := poll(timeout, interval, true, cond); !ok {
+		assert.tb.Errorf("condition was not satisfied within %s", timeout)
+	}
+}
+
+// Consistently checks that the condition is kept satisfied for the duration.
+// The condition is checked every interval.
+func (assert *Assert) Consistently(cond func() bool, duration, interval time.Duration) {
+	assert.tb.Helper()
+	assert.checkPolling(duration, interval)
+	if n, ok := poll(duration, interval, false, cond); ok {
+		assert.tb.Errorf("condition was not satisfied at #%d check within %s", n, duration)
+	}
+}
+
+// EventuallyWith checks that the callback succeeds within the timeout.
+// The callback is called every interval with a Collect, which can be used as TestingTB of any Assert.
+// The messages of the last attempt are reported on timeout.
+func (assert *Assert) EventuallyWith(callback func(c *Collect), timeout, interval time.Duration) {
+	assert.tb.Helper()
+	assert.checkPolling(timeout, interval)
+	c := &Collect{name: assert.tb.Name(), color: colorEnabled(assert.tb)}
+	if _, ok := poll(timeout, interval, true, func() bool {
+		return c.run(callback)
+	}); !ok {
+		assert.tb.Errorf("condition was not satisfied within %s, the last attempt failed with:\n%s", timeout, strings.Join(c.messages, "\n"))
+	}
+}
+
+// ConsistentlyWith checks that the callback keeps succeeding for the duration.
+// The callback is called every interval with a Collect, which can be used as TestingTB of any Assert.
+// The messages of the failed attempt are reported.
+func (assert *Assert) ConsistentlyWith(callback func(c *Collect), duration, interval time.Duration) {
+	assert.tb.Helper()
+	assert.checkPolling(duration, interval)
+	c := &Collect{name: assert.tb.Name(), color: colorEnabled(assert.tb)}
+	if n, ok := poll(duration, interval, false, func() bool {
+		return c.run(callback)
+	}); ok {
+		assert.tb.Errorf("condition was not satisfied at #%d check within %s, which failed with:\n%s", n, duration, strings.Join(c.messages, "\n"))
+	}
+}
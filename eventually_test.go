@@ -0,0 +1,230 @@
+package goassert
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCollect(t *testing.T) {
+	// Test whether Collect aborts only the current attempt.
+	c := &Collect{name: "test"}
+	if expected, got := "test", c.Name(); expected != got {
+		t.Fatalf("test: expected %q, but got %q returned by Name()", expected, got)
+	}
+	cleaned := false
+	if !c.run(func(c *Collect) {
+		c.Cleanup(func() {
+			cleaned = true
+		})
+		c.Log("hello")
+	}) {
+		t.Fatalf("test: unexpected failed attempt")
+	}
+	if !cleaned {
+		t.Fatalf("test: unexpected uncalled cleanup")
+	}
+	if c.run(func(c *Collect) {
+		c.Error("hello")
+		c.Fatalf("%s!", "world")
+		c.Error("unreachable")
+	}) {
+		t.Fatalf("test: unexpected succeeded attempt")
+	}
+	if !reflect.DeepEqual(c.messages, []string{"ERROR: hello", "FATAL: world!"}) {
+		t.Fatalf("test: unexpected messages: %#v", c.messages)
+	}
+	var panicObj interface{}
+	func() {
+		defer func() {
+			panicObj = recover()
+		}()
+		c.run(func(c *Collect) {
+			panic("hello")
+		})
+	}()
+	if panicObj != "hello" {
+		t.Fatalf("test: unexpected panic object: %#v", panicObj)
+	}
+}
+
+func TestAssertEventually(t *testing.T) {
+	// test1: Test helper registration
+	tb1 := NewHookedTestingTB("test1")
+	New(tb1).Eventually(func() bool {
+		return true
+	}, time.Second, time.Millisecond)
+	// NOTICE: We cannot identify the location of any helper exactly, because of go cover tool inserts some code into source code files.
+	if len(tb1.Helpers) != 2 {
+		t.Fatalf("test1: unexpected Helpers: %#v", tb1.Helpers)
+	}
+	// test2: only normal uses
+	tb2 := NewHookedTestingTB("test2")
+	n := 0
+	New(tb2).Eventually(func() bool {
+		n++
+		return n == 3
+	}, time.Second, time.Millisecond)
+	if n != 3 {
+		t.Fatalf("test2: unexpected number of calls: %d", n)
+	}
+	if tb2.Failed() {
+		t.Fatalf("test2: unexpected Failed() == true")
+	}
+	if len(tb2.Messages) != 0 {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	// test3: error case
+	tb3 := NewHookedTestingTB("test3")
+	New(tb3).Eventually(func() bool {
+		return false
+	}, 10*time.Millisecond, time.Millisecond)
+	if !tb3.Failed() {
+		t.Fatalf("test3: unexpected Failed() == false")
+	}
+	if !reflect.DeepEqual(tb3.Messages, []string{"ERROR: condition was not satisfied within 10ms"}) {
+		t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+	}
+	// test4: fatal exit cases
+	tb4 := NewHookedTestingTB("test4")
+	for _, durations := range [][2]time.Duration{{-time.Second, time.Millisecond}, {time.Second, 0}} {
+		func() {
+			defer func() {
+				recover()
+			}()
+			New(tb4).Eventually(func() bool {
+				return true
+			}, durations[0], durations[1])
+		}()
+	}
+	if !tb4.Failed() {
+		t.Fatalf("test4: unexpected Failed() == false")
+	}
+	if !reflect.DeepEqual(tb4.Messages, []string{"FATAL: timeout must be non-negative, but got -1s", "FATAL: interval must be positive, but got 0s"}) {
+		t.Fatalf("test4: unexpected Messages: %#v", tb4.Messages)
+	}
+}
+
+func TestAssertConsistently(t *testing.T) {
+	// test1: Test helper registration
+	tb1 := NewHookedTestingTB("test1")
+	New(tb1).Consistently(func() bool {
+		return true
+	}, 0, time.Millisecond)
+	// NOTICE: We cannot identify the location of any helper exactly, because of go cover tool inserts some code into source code files.
+	if len(tb1.Helpers) != 2 {
+		t.Fatalf("test1: unexpected Helpers: %#v", tb1.Helpers)
+	}
+	// test2: only normal uses
+	tb2 := NewHookedTestingTB("test2")
+	n := 0
+	New(tb2).Consistently(func() bool {
+		n++
+		return true
+	}, 10*time.Millisecond, time.Millisecond)
+	if n < 2 {
+		t.Fatalf("test2: unexpected number of calls: %d", n)
+	}
+	if tb2.Failed() {
+		t.Fatalf("test2: unexpected Failed() == true")
+	}
+	if len(tb2.Messages) != 0 {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	// test3: error case
+	tb3 := NewHookedTestingTB("test3")
+	n = 0
+	New(tb3).Consistently(func() bool {
+		n++
+		return n < 3
+	}, time.Second, time.Millisecond)
+	if !tb3.Failed() {
+		t.Fatalf("test3: unexpected Failed() == false")
+	}
+	if !reflect.DeepEqual(tb3.Messages, []string{"ERROR: condition was not satisfied at #3 check within 1s"}) {
+		t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+	}
+}
+
+func TestAssertEventuallyWith(t *testing.T) {
+	// test1: Test helper registration
+	tb1 := NewHookedTestingTB("test1")
+	New(tb1).EventuallyWith(func(c *Collect) {
+	}, time.Second, time.Millisecond)
+	// NOTICE: We cannot identify the location of any helper exactly, because of go cover tool inserts some code into source code files.
+	if len(tb1.Helpers) != 2 {
+		t.Fatalf("test1: unexpected Helpers: %#v", tb1.Helpers)
+	}
+	// test2: only normal uses
+	tb2 := NewHookedTestingTB("test2")
+	n := 0
+	New(tb2).EventuallyWith(func(c *Collect) {
+		n++
+		New(c, 3).Equal(n)
+	}, time.Second, time.Millisecond)
+	if tb2.Failed() {
+		t.Fatalf("test2: unexpected Failed() == true")
+	}
+	if len(tb2.Messages) != 0 {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	// test3: error case reporting the last attempt
+	tb3 := NewHookedTestingTB("test3")
+	n = 0
+	New(tb3).EventuallyWith(func(c *Collect) {
+		n++
+		New(c).SucceedNew(nil, nil)
+		if n > 1 {
+			New(c).SucceedNew(nil, errorString("hello"))
+		}
+		New(c, "world").Equal("w0rld")
+	}, 10*time.Millisecond, time.Millisecond)
+	if !tb3.Failed() {
+		t.Fatalf("test3: unexpected Failed() == false")
+	}
+	if !reflect.DeepEqual(tb3.Messages, []string{"ERROR: condition was not satisfied within 10ms, the last attempt failed with:\nFATAL: unexpected error in New-style function: hello"}) {
+		t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+	}
+}
+
+func TestAssertConsistentlyWith(t *testing.T) {
+	// test1: Test helper registration
+	tb1 := NewHookedTestingTB("test1")
+	New(tb1).ConsistentlyWith(func(c *Collect) {
+	}, 0, time.Millisecond)
+	// NOTICE: We cannot identify the location of any helper exactly, because of go cover tool inserts some code into source code files.
+	if len(tb1.Helpers) != 2 {
+		t.Fatalf("test1: unexpected Helpers: %#v", tb1.Helpers)
+	}
+	// test2: only normal uses
+	tb2 := NewHookedTestingTB("test2")
+	New(tb2).ConsistentlyWith(func(c *Collect) {
+		New(c, "hello").Equal("hello")
+	}, 10*time.Millisecond, time.Millisecond)
+	if tb2.Failed() {
+		t.Fatalf("test2: unexpected Failed() == true")
+	}
+	if len(tb2.Messages) != 0 {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	// test3: error case
+	tb3 := NewHookedTestingTB("test3")
+	n := 0
+	New(tb3).ConsistentlyWith(func(c *Collect) {
+		n++
+		New(c, 1).Equal(n)
+	}, time.Second, time.Millisecond)
+	if !tb3.Failed() {
+		t.Fatalf("test3: unexpected Failed() == false")
+	}
+	if !reflect.DeepEqual(tb3.Messages, []string{"ERROR: condition was not satisfied at #2 check within 1s, which failed with:\nERROR: at #0 value, expected 1 (int), but got 2 (int)"}) {
+		t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+	}
+}
+
+// errorString is a trivial implementation of error for tests.
+type errorString string
+
+func (err errorString) Error() string {
+	return string(err)
+}
@@ -0,0 +1,283 @@
+package goassert
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing/fstest"
+	"unicode/utf8"
+)
+
+// Fixture is the map of slash-separated paths to the contents of the files.
+// The path ending with "/" denotes the empty directory, whose content is ignored.
+// The parent directories are created implicitly.
+type Fixture map[string]string
+
+// FS returns the file system containing the files of the fixture.
+func (fixture Fixture) FS() fs.FS {
+	fsys := fstest.MapFS{}
+	for name, content := range fixture {
+		if strings.HasSuffix(name, "/") {
+			fsys[strings.TrimSuffix(name, "/")] = &fstest.MapFile{Mode: fs.ModeDir | 0755}
+		} else {
+			fsys[name] = &fstest.MapFile{Data: []byte(content), Mode: 0644}
+		}
+	}
+	return fsys
+}
+
+// TempFixture materializes the fixture into a new temporary directory, and returns the path of the directory.
+// The directory is removed by the cleanup of the testing context.
+func TempFixture(tb TestingTB, fixture Fixture) string {
+	tb.Helper()
+	names := make([]string, 0, len(fixture))
+	for name := range fixture {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !fs.ValidPath(strings.TrimSuffix(name, "/")) || name == "." || name == "./" {
+			tb.Fatalf("invalid fixture path %q", name)
+			return ""
+		}
+	}
+	return TempFixtureFS(tb, fixture.FS())
+}
+
+// TempFixtureFS copies the files in the file system into a new temporary directory, and returns the path of the directory.
+// The permissions of the files are preserved, but the directories are always created with permission 0755.
+// The directory is removed by the cleanup of the testing context.
+func TempFixtureFS(tb TestingTB, fsys fs.FS) string {
+	tb.Helper()
+	dir, err := os.MkdirTemp("", "goassert")
+	if err != nil {
+		tb.Fatalf("cannot create temporary directory: %s", err)
+		return ""
+	}
+	tb.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+	if err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(dir, filepath.FromSlash(name))
+		if d.IsDir() {
+			return os.MkdirAll(dst, 0755)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(dst, data, info.Mode().Perm())
+	}); err != nil {
+		tb.Fatalf("cannot materialize fixture: %s", err)
+		return ""
+	}
+	return dir
+}
+
+// FileExists checks that the file exists at the path, and is not a directory.
+func (assert *Assert) FileExists(path string) {
+	assert.tb.Helper()
+	if info, err := os.Stat(path); err != nil {
+		assert.tb.Errorf("expected file %q exists, but got error: %s", path, err)
+	} else if info.IsDir() {
+		assert.tb.Errorf("expected file %q exists, but got directory", path)
+	}
+}
+
+// DirExists checks that the directory exists at the path.
+func (assert *Assert) DirExists(path string) {
+	assert.tb.Helper()
+	if info, err := os.Stat(path); err != nil {
+		assert.tb.Errorf("expected directory %q exists, but got error: %s", path, err)
+	} else if !info.IsDir() {
+		assert.tb.Errorf("expected directory %q exists, but got file", path)
+	}
+}
+
+// diffContents returns the difference between the expected and actual contents.
+// The line-by-line difference is returned only if both contents are valid UTF-8 texts.
+func diffContents(expected, actual []byte, color bool) string {
+	if !(utf8.Valid(expected) && utf8.Valid(actual)) {
+		return fmt.Sprintf("binary contents differ: expected %d byte(s), but got %d byte(s)", len(expected), len(actual))
+	}
+	return diffLines(string(expected), string(actual), color)
+}
+
+// FileContent checks that the content of the file at the path equals the expected content.
+// The expected values must be one content string or []byte.
+func (assert *Assert) FileContent(path string) {
+	assert.tb.Helper()
+	if len(assert.expected) != 1 {
+		assert.tb.Fatalf("the number of the expected contents must be one")
+		return
+	}
+	var expected []byte
+	switch content := assert.expected[0].(type) {
+	case string:
+		expected = []byte(content)
+	case []byte:
+		expected = content
+	default:
+		assert.tb.Fatalf("expected content must be string or []byte, but got %T", content)
+		return
+	}
+	actual, err := os.ReadFile(path)
+	if err != nil {
+		assert.tb.Errorf("cannot read file %q: %s", path, err)
+		return
+	}
+	if !bytes.Equal(expected, actual) {
+		assert.tb.Errorf("unexpected content of file %q:\n%s", path, diffContents(expected, actual, colorEnabled(assert.tb)))
+	}
+}
+
+// FileMode checks that the mode of the file at the path equals the expected mode.
+// The expected values must be one fs.FileMode including the type bits, e.g. fs.ModeDir|0755 for the directory.
+func (assert *Assert) FileMode(path string) {
+	assert.tb.Helper()
+	if len(assert.expected) != 1 {
+		assert.tb.Fatalf("the number of the expected modes must be one")
+		return
+	}
+	expected, ok := assert.expected[0].(fs.FileMode)
+	if !ok {
+		assert.tb.Fatalf("expected mode must be fs.FileMode, but got %T", assert.expected[0])
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		assert.tb.Errorf("cannot stat file %q: %s", path, err)
+		return
+	}
+	if info.Mode() != expected {
+		assert.tb.Errorf("expected mode %s of file %q, but got %s", expected, path, info.Mode())
+	}
+}
+
+// treeEntry is the entry of the directory tree, which is a directory if content is nil.
+type treeEntry struct {
+	content []byte
+}
+
+// readTree reads the entries in the directory tree, which is a directory path string or fs.FS.
+func readTree(dir interface{}) (map[string]treeEntry, error) {
+	var fsys fs.FS
+	switch dir := dir.(type) {
+	case string:
+		if info, err := os.Stat(dir); err != nil {
+			return nil, err
+		} else if !info.IsDir() {
+			return nil, fmt.Errorf("%s is not a directory", dir)
+		}
+		fsys = os.DirFS(dir)
+	case fs.FS:
+		fsys = dir
+	default:
+		return nil, fmt.Errorf("directory must be string or fs.FS, but got %T", dir)
+	}
+	entries := map[string]treeEntry{}
+	if err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == "." {
+			return nil
+		}
+		if d.IsDir() {
+			entries[name] = treeEntry{}
+			return nil
+		}
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		if content == nil {
+			content = []byte{}
+		}
+		entries[name] = treeEntry{content: content}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// DirTreeEqual checks that the actual directory tree equals the expected directory tree.
+// The expected values must be one directory.
+// Each directory is a directory path string or fs.FS.
+// The added, removed and changed files are reported with the differences of their contents.
+func (assert *Assert) DirTreeEqual(actualDir interface{}) {
+	assert.tb.Helper()
+	if len(assert.expected) != 1 {
+		assert.tb.Fatalf("the number of the expected directories must be one")
+		return
+	}
+	expected, err := readTree(assert.expected[0])
+	if err != nil {
+		assert.tb.Fatalf("cannot read expected directory tree: %s", err)
+		return
+	}
+	actual, err := readTree(actualDir)
+	if err != nil {
+		assert.tb.Fatalf("cannot read actual directory tree: %s", err)
+		return
+	}
+	names := []string{}
+	for name := range expected {
+		names = append(names, name)
+	}
+	for name := range actual {
+		if _, ok := expected[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	kind := func(entry treeEntry) string {
+		if entry.content == nil {
+			return "directory"
+		}
+		return "file"
+	}
+	lines := []string{}
+	for _, name := range names {
+		expectedEntry, inExpected := expected[name]
+		actualEntry, inActual := actual[name]
+		switch {
+		case !inActual:
+			if dir := path.Dir(name); dir != "." {
+				if _, ok := actual[dir]; !ok {
+					// The removal of the parent directory has been reported.
+					continue
+				}
+			}
+			lines = append(lines, fmt.Sprintf("removed %s %q", kind(expectedEntry), name))
+		case !inExpected:
+			if dir := path.Dir(name); dir != "." {
+				if _, ok := expected[dir]; !ok {
+					// The addition of the parent directory has been reported.
+					continue
+				}
+			}
+			lines = append(lines, fmt.Sprintf("added %s %q", kind(actualEntry), name))
+		case kind(expectedEntry) != kind(actualEntry):
+			lines = append(lines, fmt.Sprintf("changed %q from %s to %s", name, kind(expectedEntry), kind(actualEntry)))
+		case expectedEntry.content != nil && !bytes.Equal(expectedEntry.content, actualEntry.content):
+			lines = append(lines, fmt.Sprintf("changed file %q:\n%s", name, diffContents(expectedEntry.content, actualEntry.content, colorEnabled(assert.tb))))
+		}
+	}
+	if len(lines) > 0 {
+		assert.tb.Errorf("directory trees differ:\n%s", strings.Join(lines, "\n"))
+	}
+}
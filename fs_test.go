@@ -0,0 +1,212 @@
+package goassert
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestTempFixture(t *testing.T) {
+	// test1: only normal uses
+	tb1 := NewHookedTestingTB("test1")
+	dir := TempFixture(tb1, Fixture{
+		"hello.txt":       "hello\n",
+		"sub/world.txt":   "world\n",
+		"sub/empty/":      "",
+		"sub/deep/a/b.go": "package b\n",
+	})
+	for name, content := range map[string]string{"hello.txt": "hello\n", "sub/world.txt": "world\n", "sub/deep/a/b.go": "package b\n"} {
+		if data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name))); err != nil || string(data) != content {
+			t.Fatalf("test1: unexpected content of %s: %q, %v", name, data, err)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(dir, "sub", "empty")); err != nil || !info.IsDir() {
+		t.Fatalf("test1: unexpected empty directory: %v, %v", info, err)
+	}
+	tb1.DoCleanups()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("test1: unexpected unremoved directory: %v", err)
+	}
+	if len(tb1.Messages) != 0 {
+		t.Fatalf("test1: unexpected Messages: %#v", tb1.Messages)
+	}
+	// test2: copy from io/fs.FS
+	tb2 := NewHookedTestingTB("test2")
+	dir = TempFixtureFS(tb2, fstest.MapFS{
+		"run.sh": &fstest.MapFile{Data: []byte("#!/bin/sh\n"), Mode: 0755},
+	})
+	New(tb2, fs.FileMode(0755)).FileMode(filepath.Join(dir, "run.sh"))
+	New(tb2, "#!/bin/sh\n").FileContent(filepath.Join(dir, "run.sh"))
+	tb2.DoCleanups()
+	if tb2.Failed() {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	// test3: fatal exit cases
+	for _, name := range []string{"", "/abs", "../parent", "./"} {
+		tb3 := NewHookedTestingTB("test3")
+		func() {
+			defer func() {
+				recover()
+			}()
+			TempFixture(tb3, Fixture{name: ""})
+		}()
+		if !reflect.DeepEqual(tb3.Messages, []string{"FATAL: invalid fixture path " + `"` + name + `"`}) {
+			t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+		}
+	}
+}
+
+func TestAssertFileExistsAndDirExists(t *testing.T) {
+	dir := TempFixture(t, Fixture{"file": "", "dir/": ""})
+	// test1: Test helper registration
+	tb1 := NewHookedTestingTB("test1")
+	New(tb1).FileExists(filepath.Join(dir, "file"))
+	New(tb1).DirExists(filepath.Join(dir, "dir"))
+	// NOTICE: We cannot identify the location of any helper exactly, because of go cover tool inserts some code into source code files.
+	if len(tb1.Helpers) != 2 {
+		t.Fatalf("test1: unexpected Helpers: %#v", tb1.Helpers)
+	}
+	if tb1.Failed() {
+		t.Fatalf("test1: unexpected Messages: %#v", tb1.Messages)
+	}
+	// test2: error cases
+	tb2 := NewHookedTestingTB("test2")
+	New(tb2).FileExists(filepath.Join(dir, "dir"))
+	New(tb2).DirExists(filepath.Join(dir, "file"))
+	New(tb2).FileExists(filepath.Join(dir, "none"))
+	New(tb2).DirExists(filepath.Join(dir, "none"))
+	if !reflect.DeepEqual(tb2.Messages, []string{
+		"ERROR: expected file \"" + filepath.Join(dir, "dir") + "\" exists, but got directory",
+		"ERROR: expected directory \"" + filepath.Join(dir, "file") + "\" exists, but got file",
+		"ERROR: expected file \"" + filepath.Join(dir, "none") + "\" exists, but got error: stat " + filepath.Join(dir, "none") + ": no such file or directory",
+		"ERROR: expected directory \"" + filepath.Join(dir, "none") + "\" exists, but got error: stat " + filepath.Join(dir, "none") + ": no such file or directory",
+	}) {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+}
+
+func TestAssertFileContent(t *testing.T) {
+	dir := TempFixture(t, Fixture{"hello.txt": "hello\nworld\n", "binary": "\xff\x00"})
+	hello := filepath.Join(dir, "hello.txt")
+	// test1: Test helper registration
+	tb1 := NewHookedTestingTB("test1")
+	New(tb1, "hello\nworld\n").FileContent(hello)
+	// NOTICE: We cannot identify the location of any helper exactly, because of go cover tool inserts some code into source code files.
+	if len(tb1.Helpers) != 1 {
+		t.Fatalf("test1: unexpected Helpers: %#v", tb1.Helpers)
+	}
+	// test2: only normal uses
+	tb2 := NewHookedTestingTB("test2")
+	New(tb2, []byte("hello\nworld\n")).FileContent(hello)
+	if tb2.Failed() {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	// test3: error cases followed by fatal exit cases
+	tb3 := NewHookedTestingTB("test3")
+	New(tb3, "hello\nw0rld\n").FileContent(hello)
+	New(tb3, "\xff").FileContent(filepath.Join(dir, "binary"))
+	for _, expected := range [][]interface{}{{}, {1}} {
+		func() {
+			defer func() {
+				recover()
+			}()
+			New(tb3, expected...).FileContent(hello)
+		}()
+	}
+	if !reflect.DeepEqual(tb3.Messages, []string{
+		"ERROR: unexpected content of file \"" + hello + "\":\n  hello\n- w0rld\n+ world\n  ",
+		"ERROR: unexpected content of file \"" + filepath.Join(dir, "binary") + "\":\nbinary contents differ: expected 1 byte(s), but got 2 byte(s)",
+		"FATAL: the number of the expected contents must be one",
+		"FATAL: expected content must be string or []byte, but got int",
+	}) {
+		t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+	}
+}
+
+func TestAssertFileMode(t *testing.T) {
+	dir := TempFixture(t, Fixture{"file": "", "dir/": ""})
+	// test1: only normal uses
+	tb1 := NewHookedTestingTB("test1")
+	New(tb1, fs.FileMode(0644)).FileMode(filepath.Join(dir, "file"))
+	New(tb1, fs.ModeDir|0755).FileMode(filepath.Join(dir, "dir"))
+	if tb1.Failed() {
+		t.Fatalf("test1: unexpected Messages: %#v", tb1.Messages)
+	}
+	// test2: error case followed by fatal exit cases
+	tb2 := NewHookedTestingTB("test2")
+	New(tb2, fs.FileMode(0600)).FileMode(filepath.Join(dir, "file"))
+	for _, expected := range [][]interface{}{{}, {0644}} {
+		func() {
+			defer func() {
+				recover()
+			}()
+			New(tb2, expected...).FileMode(filepath.Join(dir, "file"))
+		}()
+	}
+	if !reflect.DeepEqual(tb2.Messages, []string{
+		"ERROR: expected mode -rw------- of file \"" + filepath.Join(dir, "file") + "\", but got -rw-r--r--",
+		"FATAL: the number of the expected modes must be one",
+		"FATAL: expected mode must be fs.FileMode, but got int",
+	}) {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+}
+
+func TestAssertDirTreeEqual(t *testing.T) {
+	fixture := Fixture{
+		"README":      "hello\n",
+		"src/main.go": "package main\n",
+		"src/util/":   "",
+		"doc/a.txt":   "a\n",
+	}
+	// test1: Test helper registration
+	tb1 := NewHookedTestingTB("test1")
+	New(tb1, fixture.FS()).DirTreeEqual(TempFixture(tb1, fixture))
+	// NOTICE: We cannot identify the location of any helper exactly, because of go cover tool inserts some code into source code files.
+	if len(tb1.Helpers) != 3 {
+		t.Fatalf("test1: unexpected Helpers: %#v", tb1.Helpers)
+	}
+	if tb1.Failed() {
+		t.Fatalf("test1: unexpected Messages: %#v", tb1.Messages)
+	}
+	// test2: error case
+	tb2 := NewHookedTestingTB("test2")
+	New(tb2, TempFixture(tb2, fixture)).DirTreeEqual(Fixture{
+		"README":       "hello\nworld\n",
+		"src/main.go":  "package main\n",
+		"src/util":     "",
+		"bin/app/main": "",
+	}.FS())
+	if !reflect.DeepEqual(tb2.Messages, []string{
+		"ERROR: directory trees differ:\n" +
+			"changed file \"README\":\n  hello\n+ world\n  \n" +
+			"added directory \"bin\"\n" +
+			"removed directory \"doc\"\n" +
+			"changed \"src/util\" from directory to file",
+	}) {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	// test3: fatal exit cases
+	none := filepath.Join(t.TempDir(), "none")
+	for _, testcase := range []struct {
+		expected, actual interface{}
+		message          string
+	}{
+		{1, fixture.FS(), "FATAL: cannot read expected directory tree: directory must be string or fs.FS, but got int"},
+		{fixture.FS(), none, "FATAL: cannot read actual directory tree: stat " + none + ": no such file or directory"},
+	} {
+		tb3 := NewHookedTestingTB("test3")
+		func() {
+			defer func() {
+				recover()
+			}()
+			New(tb3, testcase.expected).DirTreeEqual(testcase.actual)
+		}()
+		if !reflect.DeepEqual(tb3.Messages, []string{testcase.message}) {
+			t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+		}
+	}
+}
@@ -0,0 +1,459 @@
+package goassert
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+)
+
+// genTree is a generated value with its lazily computed shrinks.
+// The shrinks are simpler values, and each of them has its shrinks recursively.
+type genTree[T any] struct {
+	value   T
+	shrinks func() []genTree[T]
+}
+
+// genError is the object passed to panic when a generator cannot generate any value.
+type genError struct {
+	err error
+}
+
+// genFail panics with genError.
+func genFail(format string, args ...interface{}) {
+	panic(genError{err: fmt.Errorf(format, args...)})
+}
+
+// unfoldTree returns the tree of the value whose shrinks are given by the shrinker recursively.
+func unfoldTree[T any](value T, shrink func(T) []T) genTree[T] {
+	return genTree[T]{
+		value: value,
+		shrinks: func() []genTree[T] {
+			children := []genTree[T]{}
+			for _, child := range shrink(value) {
+				children = append(children, unfoldTree(child, shrink))
+			}
+			return children
+		},
+	}
+}
+
+// mapTree returns the tree of the values mapped by the function.
+func mapTree[T, U any](tree genTree[T], f func(T) U) genTree[U] {
+	return genTree[U]{
+		value: f(tree.value),
+		shrinks: func() []genTree[U] {
+			children := []genTree[U]{}
+			for _, child := range tree.shrinks() {
+				children = append(children, mapTree(child, f))
+			}
+			return children
+		},
+	}
+}
+
+// filterTree returns the tree whose shrinks satisfy the predicate.
+// The shrinks of the rejected shrinks are also tried, because the greedy shrinking stops at the rejected ones.
+func filterTree[T any](tree genTree[T], pred func(T) bool) genTree[T] {
+	return genTree[T]{
+		value: tree.value,
+		shrinks: func() []genTree[T] {
+			children, rejected := []genTree[T]{}, []genTree[T]{}
+			for _, child := range tree.shrinks() {
+				if pred(child.value) {
+					children = append(children, filterTree(child, pred))
+				} else {
+					rejected = append(rejected, child)
+				}
+			}
+			for _, child := range rejected {
+				for _, grandchild := range child.shrinks() {
+					if pred(grandchild.value) {
+						children = append(children, filterTree(grandchild, pred))
+					}
+				}
+			}
+			return children
+		},
+	}
+}
+
+// productTree returns the tree of the values built from the trees of the parts.
+// The shrinks remove the parts if removable, and then shrink each part.
+func productTree[T, P any](parts []genTree[P], removable bool, build func([]P) T) genTree[T] {
+	values := make([]P, len(parts))
+	for i, part := range parts {
+		values[i] = part.value
+	}
+	return genTree[T]{
+		value: build(values),
+		shrinks: func() []genTree[T] {
+			children := []genTree[T]{}
+			without := func(start, end int) {
+				rest := append(append([]genTree[P]{}, parts[:start]...), parts[end:]...)
+				children = append(children, productTree(rest, removable, build))
+			}
+			if removable && len(parts) > 0 {
+				without(0, len(parts))
+				if n := len(parts); n > 1 {
+					without(n/2, n)
+					without(0, n/2)
+				}
+				for i := range parts {
+					without(i, i+1)
+				}
+			}
+			for i, part := range parts {
+				for _, child := range part.shrinks() {
+					replaced := append([]genTree[P]{}, parts...)
+					replaced[i] = child
+					children = append(children, productTree(replaced, removable, build))
+				}
+			}
+			return children
+		},
+	}
+}
+
+// Gen is a generator of random values of type T with the shrinking strategy.
+// Gen is used with ForAll.
+type Gen[T any] struct {
+	generate func(rnd *rand.Rand, size int) genTree[T]
+}
+
+// Generate returns a random value.
+// The size bounds the magnitude of the numbers and the lengths of the collections.
+func (gen Gen[T]) Generate(rnd *rand.Rand, size int) T {
+	return gen.generate(rnd, size).value
+}
+
+// NewGen returns a new Gen with the generating function and the shrinking function.
+// The shrinking function returns the candidates simpler than the given value, which may be nil.
+func NewGen[T any](generate func(rnd *rand.Rand, size int) T, shrink func(v T) []T) Gen[T] {
+	if shrink == nil {
+		shrink = func(T) []T {
+			return nil
+		}
+	}
+	return Gen[T]{
+		generate: func(rnd *rand.Rand, size int) genTree[T] {
+			return unfoldTree(generate(rnd, size), shrink)
+		},
+	}
+}
+
+// Any returns a Gen generating any value of type T by reflection as Property does.
+func Any[T any]() Gen[T] {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	return Gen[T]{
+		generate: func(rnd *rand.Rand, size int) genTree[T] {
+			v, err := generateValue(rnd, t, size)
+			if err != nil {
+				panic(genError{err: err})
+			}
+			return mapTree(unfoldTree(v, shrinkValue), func(v reflect.Value) T {
+				var value T
+				reflect.ValueOf(&value).Elem().Set(v)
+				return value
+			})
+		},
+	}
+}
+
+// Const returns a Gen generating only the value.
+func Const[T any](value T) Gen[T] {
+	return NewGen(func(*rand.Rand, int) T {
+		return value
+	}, nil)
+}
+
+// IntRange returns a Gen generating integers in [min, max].
+// The integers are shrunk toward the one nearest to zero.
+func IntRange(min, max int) Gen[int] {
+	target := 0
+	if min > 0 {
+		target = min
+	} else if max < 0 {
+		target = max
+	}
+	return NewGen(func(rnd *rand.Rand, size int) int {
+		if min > max {
+			genFail("IntRange(%d, %d) is empty", min, max)
+		}
+		span := uint64(max-min) + 1
+		if span == 0 {
+			return int(rnd.Uint64())
+		}
+		return min + int(rnd.Uint64()%span)
+	}, func(v int) []int {
+		candidates := []int{}
+		if d := v - target; d != 0 {
+			candidates = append(candidates, target)
+			if d/2 != 0 {
+				candidates = append(candidates, target+d/2)
+			}
+			if d > 0 && d-1 != 0 && d-1 != d/2 {
+				candidates = append(candidates, v-1)
+			} else if d < 0 && d+1 != 0 && d+1 != d/2 {
+				candidates = append(candidates, v+1)
+			}
+		}
+		return candidates
+	})
+}
+
+// Elements returns a Gen generating one of the values.
+// The values are shrunk toward the first one.
+func Elements[T any](values ...T) Gen[T] {
+	index := NewGen(func(rnd *rand.Rand, size int) int {
+		if len(values) == 0 {
+			genFail("Elements has no value")
+		}
+		return rnd.Intn(len(values))
+	}, func(i int) []int {
+		candidates := []int{}
+		for _, j := range []int{0, i / 2, i - 1} {
+			if j < i && (len(candidates) == 0 || candidates[len(candidates)-1] != j) {
+				candidates = append(candidates, j)
+			}
+		}
+		return candidates
+	})
+	return Map(index, func(i int) T {
+		return values[i]
+	})
+}
+
+// OneOf returns a Gen generating a value by one of the generators chosen randomly.
+// The value is shrunk by the chosen generator.
+func OneOf[T any](gens ...Gen[T]) Gen[T] {
+	return Gen[T]{
+		generate: func(rnd *rand.Rand, size int) genTree[T] {
+			if len(gens) == 0 {
+				genFail("OneOf has no generator")
+			}
+			return gens[rnd.Intn(len(gens))].generate(rnd, size)
+		},
+	}
+}
+
+// SliceOf returns a Gen generating slices whose elements are generated by the generator.
+// The length of the slices are at most the size.
+// The slices are shrunk by removing the elements, and then by shrinking each element.
+func SliceOf[T any](gen Gen[T]) Gen[[]T] {
+	return Gen[[]T]{
+		generate: func(rnd *rand.Rand, size int) genTree[[]T] {
+			elems := make([]genTree[T], rnd.Intn(size+1))
+			for i := range elems {
+				elems[i] = gen.generate(rnd, size)
+			}
+			return productTree(elems, true, func(values []T) []T {
+				return values
+			})
+		},
+	}
+}
+
+// Map returns a Gen generating the values mapped by the function from the values generated by the generator.
+// The values are shrunk by shrinking the original values.
+func Map[T, U any](gen Gen[T], f func(T) U) Gen[U] {
+	return Gen[U]{
+		generate: func(rnd *rand.Rand, size int) genTree[U] {
+			return mapTree(gen.generate(rnd, size), f)
+		},
+	}
+}
+
+// filterRetries is the max number of the consecutive values rejected by the predicate of Filter.
+const filterRetries = 100
+
+// Filter returns a Gen generating only the values satisfying the predicate.
+// The values are shrunk to the simpler values also satisfying the predicate.
+func Filter[T any](gen Gen[T], pred func(T) bool) Gen[T] {
+	return Gen[T]{
+		generate: func(rnd *rand.Rand, size int) genTree[T] {
+			for i := 0; i < filterRetries; i++ {
+				if tree := gen.generate(rnd, size); pred(tree.value) {
+					return filterTree(tree, pred)
+				}
+			}
+			genFail("Filter rejected %d consecutive values", filterRetries)
+			panic("unreachable")
+		},
+	}
+}
+
+// Bind returns a Gen generating the values by the generator returned by the function of the values generated by the generator.
+// The values are shrunk by shrinking the original values with regenerating the dependent values, and then by shrinking the dependent values.
+func Bind[T, U any](gen Gen[T], f func(T) Gen[U]) Gen[U] {
+	var bind func(tree genTree[T], seed int64, size int) genTree[U]
+	bind = func(tree genTree[T], seed int64, size int) genTree[U] {
+		inner := f(tree.value).generate(rand.New(rand.NewSource(seed)), size)
+		return genTree[U]{
+			value: inner.value,
+			shrinks: func() []genTree[U] {
+				children := []genTree[U]{}
+				for _, child := range tree.shrinks() {
+					children = append(children, bind(child, seed, size))
+				}
+				return append(children, inner.shrinks()...)
+			},
+		}
+	}
+	return Gen[U]{
+		generate: func(rnd *rand.Rand, size int) genTree[U] {
+			tree := gen.generate(rnd, size)
+			return bind(tree, rnd.Int63(), size)
+		},
+	}
+}
+
+// StructField is a generator of one field of the struct used with Struct.
+type StructField struct {
+	name     string
+	typ      reflect.Type
+	generate func(rnd *rand.Rand, size int) genTree[reflect.Value]
+}
+
+// Field returns a StructField generating the named field by the generator.
+func Field[F any](name string, gen Gen[F]) StructField {
+	return StructField{
+		name: name,
+		typ:  reflect.TypeOf((*F)(nil)).Elem(),
+		generate: func(rnd *rand.Rand, size int) genTree[reflect.Value] {
+			return mapTree(gen.generate(rnd, size), func(v F) reflect.Value {
+				return reflect.ValueOf(&v).Elem()
+			})
+		},
+	}
+}
+
+// Struct returns a Gen generating the structs whose fields are generated by the field generators.
+// The other exported fields are generated by reflection as Any does, and the other unexported fields are left zero.
+// The structs are shrunk by shrinking each field.
+func Struct[T any](fields ...StructField) Gen[T] {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	return Gen[T]{
+		generate: func(rnd *rand.Rand, size int) genTree[T] {
+			if t.Kind() != reflect.Struct {
+				genFail("Struct cannot generate non-struct type %s", t)
+			}
+			parts := make([]genTree[reflect.Value], t.NumField())
+			generated := make([]bool, t.NumField())
+			for _, field := range fields {
+				structField, ok := t.FieldByName(field.name)
+				if !ok || len(structField.Index) != 1 {
+					genFail("%s has no field %s", t, field.name)
+				}
+				if structField.PkgPath != "" {
+					genFail("%s.%s is unexported", t, field.name)
+				}
+				if !field.typ.AssignableTo(structField.Type) {
+					genFail("cannot assign %s to %s.%s of type %s", field.typ, t, field.name, structField.Type)
+				}
+				parts[structField.Index[0]] = field.generate(rnd, size)
+				generated[structField.Index[0]] = true
+			}
+			for i := range parts {
+				if generated[i] {
+					continue
+				}
+				if t.Field(i).PkgPath != "" {
+					parts[i] = unfoldTree(reflect.Zero(t.Field(i).Type), shrinkValue)
+					continue
+				}
+				v, err := generateValue(rnd, t.Field(i).Type, size)
+				if err != nil {
+					genFail("cannot generate %s.%s: %s", t, t.Field(i).Name, err)
+				}
+				parts[i] = unfoldTree(v, shrinkValue)
+			}
+			return productTree(parts, false, func(values []reflect.Value) T {
+				v := reflect.New(t).Elem()
+				for i, value := range values {
+					if t.Field(i).PkgPath == "" {
+						v.Field(i).Set(value)
+					}
+				}
+				return v.Interface().(T)
+			})
+		},
+	}
+}
+
+// generateTree generates a tree by the generator, and returns the error if the generator cannot generate any value.
+func generateTree[T any](gen Gen[T], rnd *rand.Rand, size int) (tree genTree[T], err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			e, ok := v.(genError)
+			if !ok {
+				panic(v)
+			}
+			err = e.err
+		}
+	}()
+	return gen.generate(rnd, size), nil
+}
+
+// shrinksOf returns the shrinks of the tree, or no shrink if the generator cannot generate any value.
+func shrinksOf[T any](tree genTree[T]) (shrinks []genTree[T]) {
+	defer func() {
+		if v := recover(); v != nil {
+			if _, ok := v.(genError); !ok {
+				panic(v)
+			}
+			shrinks = nil
+		}
+	}()
+	return tree.shrinks()
+}
+
+// ForAll checks that the property holds for the values generated by the generator.
+// The failing value is shrunk to a minimal counterexample, which is reported with the seed reproducing it.
+// See PropertyConfig for the default configuration.
+func ForAll[T any](tb TestingTB, gen Gen[T], property func(a *Assert, v T)) {
+	tb.Helper()
+	ForAllWithConfig(tb, &PropertyConfig{}, gen, property)
+}
+
+// ForAllWithConfig checks that the property holds for the values generated by the generator with the configuration.
+// See ForAll for details.
+func ForAllWithConfig[T any](tb TestingTB, config *PropertyConfig, gen Gen[T], property func(a *Assert, v T)) {
+	tb.Helper()
+	runs, maxShrinks := config.limits()
+	seed := config.seed(tb)
+	rnd := rand.New(rand.NewSource(seed))
+	check := func(v T) *Collect {
+		return checkProperty(tb, func(a *Assert) {
+			property(a, v)
+		})
+	}
+	for run := 0; run < runs; run++ {
+		tree, err := generateTree(gen, rnd, run*100/runs)
+		if err != nil {
+			tb.Fatalf("cannot generate value: %s", err)
+			return
+		}
+		c := check(tree.value)
+		if !c.failed {
+			continue
+		}
+		shrinks, evaluations := 0, 0
+		for improved := true; improved && evaluations < maxShrinks; {
+			improved = false
+			for _, child := range shrinksOf(tree) {
+				if evaluations >= maxShrinks {
+					break
+				}
+				evaluations++
+				if childCollect := check(child.value); childCollect.failed {
+					tree, c = child, childCollect
+					shrinks++
+					improved = true
+					break
+				}
+			}
+		}
+		reportPropertyFailure(tb, run+1, seed, shrinks, "  "+formatValue(tree.value), c)
+		return
+	}
+}
@@ -0,0 +1,165 @@
+package goassert
+
+import (
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestGenGenerate(t *testing.T) {
+	type point struct {
+		X, Y  int
+		Label string
+		tag   string
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		if v := IntRange(-3, 5).Generate(rnd, 100); !(-3 <= v && v <= 5) {
+			t.Fatalf("test: unexpected IntRange value: %d", v)
+		}
+		if v := Elements("a", "b").Generate(rnd, 100); !(v == "a" || v == "b") {
+			t.Fatalf("test: unexpected Elements value: %q", v)
+		}
+		if v := OneOf(Const(1), Const(2)).Generate(rnd, 100); !(v == 1 || v == 2) {
+			t.Fatalf("test: unexpected OneOf value: %d", v)
+		}
+		if v := SliceOf(Any[string]()).Generate(rnd, 10); len(v) > 10 {
+			t.Fatalf("test: unexpected SliceOf value: %#v", v)
+		}
+		if v := Filter(Any[int](), func(v int) bool { return v%2 == 0 }).Generate(rnd, 100); v%2 != 0 {
+			t.Fatalf("test: unexpected Filter value: %d", v)
+		}
+		if v := Bind(IntRange(0, 5), func(n int) Gen[[2]int] {
+			return Map(IntRange(n, 10), func(m int) [2]int {
+				return [2]int{n, m}
+			})
+		}).Generate(rnd, 100); !(0 <= v[0] && v[0] <= 5 && v[0] <= v[1] && v[1] <= 10) {
+			t.Fatalf("test: unexpected Bind value: %#v", v)
+		}
+		if v := Struct[point](Field("X", IntRange(0, 9)), Field("Label", Const("p"))).Generate(rnd, 100); !(0 <= v.X && v.X <= 9 && v.Label == "p" && v.tag == "") {
+			t.Fatalf("test: unexpected Struct value: %#v", v)
+		}
+	}
+}
+
+func TestGenShrink(t *testing.T) {
+	for _, testcase := range []struct {
+		gen      Gen[int]
+		v        int
+		expected []int
+	}{
+		{IntRange(-10, 10), 10, []int{0, 5, 9}},
+		{IntRange(-10, 10), -3, []int{0, -1, -2}},
+		{IntRange(5, 10), 9, []int{5, 7, 8}},
+		{IntRange(-10, -5), -6, []int{-5}},
+		{IntRange(5, 10), 5, []int{}},
+	} {
+		// Find the seed generating the value.
+		for seed := int64(0); ; seed++ {
+			tree := testcase.gen.generate(rand.New(rand.NewSource(seed)), 100)
+			if tree.value != testcase.v {
+				continue
+			}
+			got := []int{}
+			for _, child := range tree.shrinks() {
+				got = append(got, child.value)
+			}
+			if !reflect.DeepEqual(testcase.expected, got) {
+				t.Fatalf("test: expected %#v, but got %#v as shrinks of %d", testcase.expected, got, testcase.v)
+			}
+			break
+		}
+	}
+}
+
+func TestForAll(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+	// test1: Test helper registration
+	tb1 := NewHookedTestingTB("test1")
+	ForAll(tb1, Any[int](), func(a *Assert, v int) {
+	})
+	// NOTICE: We cannot identify the location of any helper exactly, because of go cover tool inserts some code into source code files.
+	if len(tb1.Helpers) != 3 {
+		t.Fatalf("test1: unexpected Helpers: %#v", tb1.Helpers)
+	}
+	// test2: only normal uses
+	tb2 := NewHookedTestingTB("test2")
+	ForAll(tb2, SliceOf(IntRange(0, 9)), func(a *Assert, s []int) {
+		for _, v := range s {
+			a.New(true).Equal(0 <= v && v <= 9)
+		}
+	})
+	if tb2.Failed() {
+		t.Fatalf("test2: unexpected Failed() == true")
+	}
+	if len(tb2.Messages) != 0 {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	// test3: error cases with shrinking
+	tb3 := NewHookedTestingTB("test3")
+	config := &PropertyConfig{Seed: 1}
+	ForAllWithConfig(tb3, config, IntRange(5, 1000), func(a *Assert, v int) {
+		a.New(true).Equal(v < 100)
+	})
+	ForAllWithConfig(tb3, config, SliceOf(IntRange(0, 9)), func(a *Assert, s []int) {
+		a.New(true).Equal(len(s) < 3)
+	})
+	ForAllWithConfig(tb3, config, Filter(IntRange(0, 1000), func(v int) bool { return v%2 == 1 }), func(a *Assert, v int) {
+		a.New(true).Equal(v < 100)
+	})
+	ForAllWithConfig(tb3, config, Map(IntRange(0, 1000), strconv.Itoa), func(a *Assert, s string) {
+		a.New(true).Equal(len(s) < 3)
+	})
+	ForAllWithConfig(tb3, config, Struct[point](Field("X", IntRange(0, 100))), func(a *Assert, p point) {
+		a.New(true).Equal(p.X < 50)
+	})
+	if !tb3.Failed() {
+		t.Fatalf("test3: unexpected Failed() == false")
+	}
+	if len(tb3.Messages) != 5 {
+		t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+	}
+	for i, counterexample := range []string{
+		"  100 (int)",
+		"  []int{0, 0, 0} ([]int)",
+		"  101 (int)",
+		"  \"100\" (string)",
+		"  goassert.point{X: 50, Y: 0} (goassert.point)",
+	} {
+		if !(strings.HasPrefix(tb3.Messages[i], "ERROR: property failed after ") && strings.Contains(tb3.Messages[i], " (seed 1, rerun with GOASSERT_SEED=1)\nminimal counterexample (shrunk ") &&
+			strings.HasSuffix(tb3.Messages[i], counterexample+"\nfailed with:\nERROR: at #0 value, expected true (bool), but got false (bool)")) {
+			t.Fatalf("test3: unexpected Messages[%d]: %#v", i, tb3.Messages[i])
+		}
+	}
+	// test4: fatal exit cases
+	for i, testcase := range []struct {
+		gen     Gen[point]
+		message string
+	}{
+		{Map(IntRange(2, 1), func(int) point { return point{} }), "FATAL: cannot generate value: IntRange(2, 1) is empty"},
+		{Map(Elements[int](), func(int) point { return point{} }), "FATAL: cannot generate value: Elements has no value"},
+		{OneOf[point](), "FATAL: cannot generate value: OneOf has no generator"},
+		{Filter(Any[point](), func(point) bool { return false }), "FATAL: cannot generate value: Filter rejected 100 consecutive values"},
+		{Struct[point](Field("Z", IntRange(0, 1))), "FATAL: cannot generate value: goassert.point has no field Z"},
+		{Struct[point](Field("X", Const("x"))), "FATAL: cannot generate value: cannot assign string to goassert.point.X of type int"},
+	} {
+		tb4 := NewHookedTestingTB("test4")
+		func() {
+			defer func() {
+				recover()
+			}()
+			ForAll(tb4, testcase.gen, func(a *Assert, p point) {
+			})
+		}()
+		if !tb4.Failed() {
+			t.Fatalf("test4 #%d: unexpected Failed() == false", i)
+		}
+		if !reflect.DeepEqual(tb4.Messages, []string{testcase.message}) {
+			t.Fatalf("test4 #%d: unexpected Messages: %#v", i, tb4.Messages)
+		}
+	}
+}
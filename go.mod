@@ -0,0 +1,3 @@
+module github.com/hiro4bbh/go-assert
+
+go 1.22
@@ -0,0 +1,20 @@
+// Command goassertvet reports the misuses of package goassert detectable at compile time.
+//
+// Usage:
+//
+//	goassertvet [flags] [packages]
+//
+// goassertvet runs the analyzer of package goassertvet on the packages including their test files.
+// See package goassertvet for the reported misuses.
+// goassertvet can also be run via go vet -vettool=$(which goassertvet).
+// goassertvet is in its own module github.com/hiro4bbh/go-assert/goassertvet depending on golang.org/x/tools, so package goassert depends on no module.
+package main
+
+import (
+	"github.com/hiro4bbh/go-assert/goassertvet"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(goassertvet.Analyzer)
+}
@@ -0,0 +1,191 @@
+// Package goassertvet provides the analyzer reporting the misuses of package goassert detectable at compile time.
+//
+// The following calls are reported:
+//
+//   - Equal called with the number of the values different from the one passed to New.
+//   - EqualWithoutError called with the number of the values except for the error different from the one passed to New.
+//   - ExpectError or EqualWithoutError called with the last value which is not an error.
+//   - ExpectError with more than one error pattern passed to New.
+//   - ExpectPanic with no or more than one value passed to New.
+//
+// The number of the expected values is known only if Assert is created in the same expression, e.g. New(t, 1, 2).Equal(f()).
+// See command goassertvet for the standalone checker.
+package goassertvet
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// goassertPath is the import path of package goassert.
+const goassertPath = "github.com/hiro4bbh/go-assert"
+
+// Analyzer reports the misuses of package goassert.
+var Analyzer = &analysis.Analyzer{
+	Name: "goassertvet",
+	Doc:  "report the misuses of package goassert detectable at compile time",
+	Run:  run,
+}
+
+// checker checks the calls of package goassert in the type-checked files of the pass.
+type checker struct {
+	pass *analysis.Pass
+	info *types.Info
+}
+
+// run reports the misuses in the files of the pass.
+func run(pass *analysis.Pass) (interface{}, error) {
+	c := &checker{pass: pass, info: pass.TypesInfo}
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			if call, ok := node.(*ast.CallExpr); ok {
+				c.checkCall(call)
+			}
+			return true
+		})
+	}
+	return nil, nil
+}
+
+// reportf reports the diagnostic at the position.
+func (c *checker) reportf(pos token.Pos, format string, args ...interface{}) {
+	c.pass.Reportf(pos, format, args...)
+}
+
+// calledFunc returns the called function or method, or nil if the callee is not a declared function.
+func (c *checker) calledFunc(call *ast.CallExpr) *types.Func {
+	var obj types.Object
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		obj = c.info.Uses[fun]
+	case *ast.SelectorExpr:
+		if sel, ok := c.info.Selections[fun]; ok {
+			obj = sel.Obj()
+		} else {
+			obj = c.info.Uses[fun.Sel]
+		}
+	}
+	fn, _ := obj.(*types.Func)
+	return fn
+}
+
+// isGoassert returns true if the function is the function of package goassert with the name, or the method of the type with the name if recv is not empty.
+func isGoassert(fn *types.Func, recv, name string) bool {
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != goassertPath || fn.Name() != name {
+		return false
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Recv() == nil {
+		return recv == ""
+	}
+	t := sig.Recv().Type()
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Name() == recv
+}
+
+// valueTypes returns the types of the values passed as the arguments, or nil if they are unknown.
+// The only argument of the multi-valued call is expanded into its values.
+func (c *checker) valueTypes(call *ast.CallExpr, args []ast.Expr) []types.Type {
+	if call.Ellipsis.IsValid() {
+		return nil
+	}
+	if len(args) == 1 {
+		if tuple, ok := c.info.TypeOf(args[0]).(*types.Tuple); ok {
+			ts := make([]types.Type, tuple.Len())
+			for i := range ts {
+				ts[i] = tuple.At(i).Type()
+			}
+			return ts
+		}
+	}
+	ts := make([]types.Type, len(args))
+	for i, arg := range args {
+		if ts[i] = c.info.TypeOf(arg); ts[i] == nil {
+			return nil
+		}
+	}
+	return ts
+}
+
+// expectedCount returns the number of the expected values of Assert created by the expression, or -1 if it is unknown.
+func (c *checker) expectedCount(x ast.Expr) int {
+	call, ok := ast.Unparen(x).(*ast.CallExpr)
+	if !ok {
+		return -1
+	}
+	fn := c.calledFunc(call)
+	switch {
+	case isGoassert(fn, "", "New"):
+		if call.Ellipsis.IsValid() || len(call.Args) < 1 {
+			return -1
+		}
+		return len(call.Args) - 1
+	case isGoassert(fn, "Assert", "New"):
+		if ts := c.valueTypes(call, call.Args); ts != nil {
+			return len(ts)
+		}
+	case isGoassert(fn, "Assert", "WithOptions"):
+		if sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr); ok {
+			return c.expectedCount(sel.X)
+		}
+	}
+	return -1
+}
+
+// errorType is the type of the interface error.
+var errorType = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+// isErrorType returns true if the value of the type may be an error.
+// The interface types are regarded as they may hold the errors.
+func isErrorType(t types.Type) bool {
+	if basic, ok := t.(*types.Basic); ok && basic.Kind() == types.UntypedNil {
+		return true
+	}
+	return types.IsInterface(t) || types.Implements(t, errorType)
+}
+
+// checkCall checks the call if it is the method call of Assert.
+func (c *checker) checkCall(call *ast.CallExpr) {
+	fn := c.calledFunc(call)
+	if fn == nil {
+		return
+	}
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+	expected := c.expectedCount(sel.X)
+	actual := c.valueTypes(call, call.Args)
+	switch {
+	case isGoassert(fn, "Assert", "Equal"):
+		if expected >= 0 && actual != nil && expected != len(actual) {
+			c.reportf(call.Lparen, "Equal is called with %d value(s), but New is given %d expected value(s)", len(actual), expected)
+		}
+	case isGoassert(fn, "Assert", "EqualWithoutError"):
+		if actual == nil || len(actual) == 0 {
+			return
+		}
+		if !isErrorType(actual[len(actual)-1]) {
+			c.reportf(call.Lparen, "the last value passed to EqualWithoutError must be error, but got %s", actual[len(actual)-1])
+		} else if expected >= 0 && expected != len(actual)-1 {
+			c.reportf(call.Lparen, "EqualWithoutError is called with %d value(s) except for the error, but New is given %d expected value(s)", len(actual)-1, expected)
+		}
+	case isGoassert(fn, "Assert", "ExpectError"):
+		if expected > 1 {
+			c.reportf(call.Lparen, "ExpectError accepts at most one error pattern, but New is given %d expected value(s)", expected)
+		}
+		if actual != nil && len(actual) > 0 && !isErrorType(actual[len(actual)-1]) {
+			c.reportf(call.Lparen, "the last value passed to ExpectError must be error, but got %s", actual[len(actual)-1])
+		}
+	case isGoassert(fn, "Assert", "ExpectPanic"):
+		if expected == 0 || expected > 1 {
+			c.reportf(call.Lparen, "ExpectPanic needs one value passed to panic, but New is given %d expected value(s)", expected)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package goassertvet_test
+
+import (
+	"testing"
+
+	"github.com/hiro4bbh/go-assert/goassertvet"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), goassertvet.Analyzer, "example")
+}
@@ -0,0 +1,43 @@
+package example
+
+import (
+	"errors"
+	"testing"
+
+	goassert "github.com/hiro4bbh/go-assert"
+)
+
+func pair() (int, int) {
+	return 1, 2
+}
+
+func pairWithError() (int, int, error) {
+	return 1, 2, nil
+}
+
+func single() (string, bool) {
+	return "", true
+}
+
+func TestExample(t *testing.T) {
+	goassert.New(t, 1, 2).Equal(pair())
+	goassert.New(t, 1, 2).Equal(1, 2)
+	goassert.New(t, 1, 2).Equal(pairWithError()) // want "Equal is called with 3 value\\(s\\), but New is given 2 expected value\\(s\\)"
+	goassert.New(t, 1).WithOptions().Equal(1, 2) // want "Equal is called with 2 value\\(s\\), but New is given 1 expected value\\(s\\)"
+	goassert.New(t).New(pair()).Equal(1)         // want "Equal is called with 1 value\\(s\\), but New is given 2 expected value\\(s\\)"
+	values := []interface{}{1, 2}
+	goassert.New(t, values...).Equal(1)
+	goassert.New(t, 1).Equal(values...)
+	a := goassert.New(t, 1)
+	a.Equal(1, 2)
+	goassert.New(t, 1, 2).EqualWithoutError(pairWithError())
+	goassert.New(t, 1).EqualWithoutError(pairWithError()) // want "EqualWithoutError is called with 2 value\\(s\\) except for the error, but New is given 1 expected value\\(s\\)"
+	goassert.New(t, "").EqualWithoutError(single())       // want "the last value passed to EqualWithoutError must be error, but got bool"
+	goassert.New(t).ExpectError(errors.New("error"))
+	goassert.New(t, "pattern").ExpectError(1, nil)
+	goassert.New(t, "pattern", "pattern").ExpectError(nil) // want "ExpectError accepts at most one error pattern, but New is given 2 expected value\\(s\\)"
+	goassert.New(t).ExpectError(single())                  // want "the last value passed to ExpectError must be error, but got bool"
+	goassert.New(t, "panic").ExpectPanic(func() {})
+	goassert.New(t).ExpectPanic(func() {})       // want "ExpectPanic needs one value passed to panic, but New is given 0 expected value\\(s\\)"
+	goassert.New(t, 1, 2).ExpectPanic(func() {}) // want "ExpectPanic needs one value passed to panic, but New is given 2 expected value\\(s\\)"
+}
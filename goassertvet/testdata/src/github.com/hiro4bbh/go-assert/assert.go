@@ -0,0 +1,30 @@
+// Package goassert is the stub of package goassert for the tests of goassertvet.
+package goassert
+
+type TestingTB interface {
+	Helper()
+}
+
+type CompareOption func()
+
+type Assert struct{}
+
+func New(tb TestingTB, expected ...interface{}) *Assert {
+	return &Assert{}
+}
+
+func (assert *Assert) New(expected ...interface{}) *Assert {
+	return assert
+}
+
+func (assert *Assert) WithOptions(options ...CompareOption) *Assert {
+	return assert
+}
+
+func (assert *Assert) Equal(actual ...interface{}) {}
+
+func (assert *Assert) EqualWithoutError(actualErr ...interface{}) {}
+
+func (assert *Assert) ExpectError(_err ...interface{}) {}
+
+func (assert *Assert) ExpectPanic(callback func()) {}
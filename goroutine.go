@@ -0,0 +1,95 @@
+package goassert
+
+import (
+	"runtime"
+	"strings"
+	"time"
+)
+
+// goroutineLeakTimeout is the timeout waiting for the goroutines started in the test to exit.
+var goroutineLeakTimeout = time.Second
+
+// goroutineLeakInterval is the interval checking the remaining goroutines.
+var goroutineLeakInterval = 10 * time.Millisecond
+
+// goroutineStack is a stack dump of one goroutine.
+type goroutineStack struct {
+	// id is the goroutine ID.
+	id string
+	// topFunc is the name of the function at the top of the stack.
+	topFunc string
+	// dump is the whole stack dump.
+	dump string
+}
+
+// snapshotGoroutines returns the stack dumps of all the goroutines.
+func snapshotGoroutines() []goroutineStack {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	stacks := []goroutineStack{}
+	for _, dump := range strings.Split(strings.TrimSpace(string(buf)), "\n\n") {
+		lines := strings.SplitN(dump, "\n", 3)
+		// The header line is "goroutine ID [STATUS]:".
+		header := strings.Fields(lines[0])
+		if len(header) < 2 || header[0] != "goroutine" {
+			continue
+		}
+		stack := goroutineStack{id: header[1], dump: dump}
+		if len(lines) > 1 {
+			stack.topFunc = lines[1]
+			if i := strings.LastIndexByte(stack.topFunc, '('); i > 0 {
+				stack.topFunc = stack.topFunc[:i]
+			}
+		}
+		stacks = append(stacks, stack)
+	}
+	return stacks
+}
+
+// NoGoroutineLeaks checks that no goroutine started after calling this remains at the cleanup of the test.
+// The goroutines whose top function names are in ignores are not reported.
+// The remaining goroutines are waited for a while, because they may be exiting at the cleanup.
+func NoGoroutineLeaks(tb TestingTB, ignores ...string) {
+	tb.Helper()
+	initial := map[string]bool{}
+	for _, stack := range snapshotGoroutines() {
+		initial[stack.id] = true
+	}
+	tb.Cleanup(func() {
+		tb.Helper()
+		var leaked []goroutineStack
+		poll(goroutineLeakTimeout, goroutineLeakInterval, true, func() bool {
+			leaked = []goroutineStack{}
+			for _, stack := range snapshotGoroutines() {
+				if initial[stack.id] {
+					continue
+				}
+				ignored := false
+				for _, ignore := range ignores {
+					if stack.topFunc == ignore {
+						ignored = true
+						break
+					}
+				}
+				if !ignored {
+					leaked = append(leaked, stack)
+				}
+			}
+			return len(leaked) == 0
+		})
+		if len(leaked) > 0 {
+			dumps := make([]string, len(leaked))
+			for i, stack := range leaked {
+				dumps[i] = stack.dump
+			}
+			tb.Errorf("found %d leaked goroutine(s):\n\n%s", len(leaked), strings.Join(dumps, "\n\n"))
+		}
+	})
+}
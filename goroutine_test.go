@@ -0,0 +1,60 @@
+package goassert
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// blockUntilClosed is the leaking goroutine used in the tests.
+func blockUntilClosed(ch chan struct{}) {
+	<-ch
+}
+
+func TestNoGoroutineLeaks(t *testing.T) {
+	defer func(timeout time.Duration) {
+		goroutineLeakTimeout = timeout
+	}(goroutineLeakTimeout)
+	goroutineLeakTimeout = 50 * time.Millisecond
+	// test1: only normal uses
+	tb1 := NewHookedTestingTB("test1")
+	NoGoroutineLeaks(tb1)
+	done := make(chan struct{})
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(done)
+	}()
+	tb1.DoCleanups()
+	if tb1.Failed() {
+		t.Fatalf("test1: unexpected Failed() == true")
+	}
+	if len(tb1.Messages) != 0 {
+		t.Fatalf("test1: unexpected Messages: %#v", tb1.Messages)
+	}
+	// test2: ignored leaked goroutine
+	ch := make(chan struct{})
+	defer close(ch)
+	tb2 := NewHookedTestingTB("test2")
+	NoGoroutineLeaks(tb2, runtime.FuncForPC(reflect.ValueOf(blockUntilClosed).Pointer()).Name())
+	go blockUntilClosed(ch)
+	tb2.DoCleanups()
+	if tb2.Failed() {
+		t.Fatalf("test2: unexpected Failed() == true")
+	}
+	if len(tb2.Messages) != 0 {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	// test3: error case
+	tb3 := NewHookedTestingTB("test3")
+	NoGoroutineLeaks(tb3)
+	go blockUntilClosed(ch)
+	tb3.DoCleanups()
+	if !tb3.Failed() {
+		t.Fatalf("test3: unexpected Failed() == false")
+	}
+	if !(len(tb3.Messages) == 1 && strings.HasPrefix(tb3.Messages[0], "ERROR: found 1 leaked goroutine(s):\n\ngoroutine ") && strings.Contains(tb3.Messages[0], "blockUntilClosed(")) {
+		t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+	}
+}
@@ -0,0 +1,282 @@
+package goassert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// HTTPRequest is the builder of the request driving http.Handler.
+type HTTPRequest struct {
+	method string
+	target string
+	query  url.Values
+	header http.Header
+	body   []byte
+	err    error
+}
+
+// NewHTTPRequest returns a new HTTPRequest with the method and the target.
+// The target is the request URI such as "/path?key=value", or the absolute URL.
+func NewHTTPRequest(method, target string) *HTTPRequest {
+	return &HTTPRequest{
+		method: method,
+		target: target,
+		query:  url.Values{},
+		header: http.Header{},
+	}
+}
+
+// WithHeader adds the header field, and returns the request.
+func (req *HTTPRequest) WithHeader(key, value string) *HTTPRequest {
+	req.header.Add(key, value)
+	return req
+}
+
+// WithQuery adds the query parameter to the target, and returns the request.
+func (req *HTTPRequest) WithQuery(key, value string) *HTTPRequest {
+	req.query.Add(key, value)
+	return req
+}
+
+// WithBody sets the body, and returns the request.
+func (req *HTTPRequest) WithBody(body string) *HTTPRequest {
+	req.body = []byte(body)
+	return req
+}
+
+// WithJSON sets the body to the JSON encoding of v with header Content-Type "application/json", and returns the request.
+func (req *HTTPRequest) WithJSON(v interface{}) *HTTPRequest {
+	req.body, req.err = json.Marshal(v)
+	req.header.Set("Content-Type", "application/json")
+	return req
+}
+
+// build builds the request for http.Handler as httptest.NewRequest does.
+func (req *HTTPRequest) build() (*http.Request, error) {
+	if req.err != nil {
+		return nil, req.err
+	}
+	u, err := url.Parse(req.target)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.query) > 0 {
+		query := u.Query()
+		for key, values := range req.query {
+			query[key] = append(query[key], values...)
+		}
+		u.RawQuery = query.Encode()
+	}
+	r, err := http.NewRequest(req.method, u.String(), bytes.NewReader(req.body))
+	if err != nil {
+		return nil, err
+	}
+	r.RequestURI = u.RequestURI()
+	if u.Host == "" {
+		r.Host = "example.com"
+	}
+	r.RemoteAddr = "192.0.2.1:1234"
+	for key, values := range req.header {
+		r.Header[key] = append([]string{}, values...)
+	}
+	if r.Header.Get("Host") != "" {
+		r.Host = r.Header.Get("Host")
+	}
+	return r, nil
+}
+
+// HTTPResponse is the response recorded from http.Handler.
+// The expectations are checked by its methods, which report the failure with the full request and response dumps.
+type HTTPResponse struct {
+	tb TestingTB
+	// Response is the recorded response.
+	Response *http.Response
+	// Body is the recorded response body.
+	Body        []byte
+	requestDump string
+}
+
+// ServeHTTP drives the handler with the request, and returns the recorded response.
+func ServeHTTP(tb TestingTB, handler http.Handler, req *HTTPRequest) *HTTPResponse {
+	tb.Helper()
+	r, err := req.build()
+	if err != nil {
+		tb.Fatalf("cannot build request: %s", err)
+		return nil
+	}
+	requestDump, err := httputil.DumpRequest(r, true)
+	if err != nil {
+		tb.Fatalf("cannot dump request: %s", err)
+		return nil
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, r)
+	response := recorder.Result()
+	body, _ := io.ReadAll(response.Body)
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	return &HTTPResponse{
+		tb:          tb,
+		Response:    response,
+		Body:        body,
+		requestDump: string(requestDump),
+	}
+}
+
+// errorf reports the failure with the request and response dumps.
+func (res *HTTPResponse) errorf(format string, args ...interface{}) {
+	res.tb.Helper()
+	responseDump, err := httputil.DumpResponse(res.Response, false)
+	if err != nil {
+		responseDump = []byte(fmt.Sprintf("cannot dump response: %s\n", err))
+	}
+	res.tb.Errorf("%s\n\nrequest:\n%s\n\nresponse:\n%s%s", fmt.Sprintf(format, args...), bytes.TrimRight([]byte(res.requestDump), "\r\n"), responseDump, res.Body)
+}
+
+// ExpectStatus checks that the status code equals the expected code, and returns the response.
+func (res *HTTPResponse) ExpectStatus(code int) *HTTPResponse {
+	res.tb.Helper()
+	if res.Response.StatusCode != code {
+		res.errorf("expected status %d %s, but got %d %s", code, http.StatusText(code), res.Response.StatusCode, http.StatusText(res.Response.StatusCode))
+	}
+	return res
+}
+
+// ExpectHeader checks that the header field equals the expected value, and returns the response.
+func (res *HTTPResponse) ExpectHeader(key, value string) *HTTPResponse {
+	res.tb.Helper()
+	if values := res.Response.Header.Values(key); !(len(values) == 1 && values[0] == value) {
+		res.errorf("expected header %s %q, but got %q", http.CanonicalHeaderKey(key), value, values)
+	}
+	return res
+}
+
+// ExpectHeaderMatches checks that the header field matches the expected pattern, and returns the response.
+func (res *HTTPResponse) ExpectHeaderMatches(key, pattern string) *HTTPResponse {
+	res.tb.Helper()
+	value := res.Response.Header.Get(key)
+	if matched, err := matchPattern("header", pattern, value); err != nil {
+		res.tb.Fatalf("%s", err)
+	} else if !matched {
+		res.errorf("expected header %s pattern %q, but got %q", http.CanonicalHeaderKey(key), pattern, value)
+	}
+	return res
+}
+
+// ExpectBody checks that the body equals the expected body, and returns the response.
+func (res *HTTPResponse) ExpectBody(body string) *HTTPResponse {
+	res.tb.Helper()
+	if string(res.Body) != body {
+		res.errorf("unexpected body:\n%s", diffLines(body, string(res.Body), colorEnabled(res.tb)))
+	}
+	return res
+}
+
+// ExpectBodyMatches checks that the body matches the expected pattern, and returns the response.
+func (res *HTTPResponse) ExpectBodyMatches(pattern string) *HTTPResponse {
+	res.tb.Helper()
+	if matched, err := matchPattern("body", pattern, string(res.Body)); err != nil {
+		res.tb.Fatalf("%s", err)
+	} else if !matched {
+		res.errorf("expected body pattern %q, but got body %q", pattern, res.Body)
+	}
+	return res
+}
+
+// normalizeJSONNumbers returns the decoded JSON value whose numbers are replaced with their canonical texts.
+// The integers are written without the fraction and exponent, e.g. 1.0e2 as 100, and the other numbers are written in the shortest exact decimal.
+// Thus, the numbers are compared exactly, e.g. 9007199254740993 differs from 9007199254740992 unlike float64.
+func normalizeJSONNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		// The huge exponents are left as they are, because their exact values are too large.
+		if i := strings.IndexAny(string(v), "eE"); i >= 0 {
+			if exp, err := strconv.Atoi(string(v[i+1:])); err != nil || exp < -1000 || exp > 1000 {
+				return v
+			}
+		}
+		r, ok := new(big.Rat).SetString(string(v))
+		if !ok {
+			return v
+		}
+		if r.IsInt() {
+			return json.Number(r.Num().String())
+		}
+		s := strings.TrimRight(r.FloatString(r.Denom().BitLen()), "0")
+		return json.Number(s)
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = normalizeJSONNumbers(elem)
+		}
+	case map[string]interface{}:
+		for key, elem := range v {
+			v[key] = normalizeJSONNumbers(elem)
+		}
+	}
+	return v
+}
+
+// normalizeJSON returns the decoded JSON value and its indented encoding.
+// The document string or []byte is decoded, and the other value is converted via its JSON encoding.
+// The numbers are decoded as json.Number in the canonical texts, see normalizeJSONNumbers.
+func normalizeJSON(v interface{}) (interface{}, string, error) {
+	var src []byte
+	switch v := v.(type) {
+	case string:
+		src = []byte(v)
+	case []byte:
+		src = v
+	default:
+		var err error
+		if src, err = json.Marshal(v); err != nil {
+			return nil, "", err
+		}
+	}
+	// The document is validated before decoded with UseNumber, so that the errors are the same as json.Unmarshal.
+	var raw json.RawMessage
+	if err := json.Unmarshal(src, &raw); err != nil {
+		return nil, "", err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, "", err
+	}
+	decoded = normalizeJSONNumbers(decoded)
+	indented, err := json.MarshalIndent(decoded, "", "  ")
+	if err != nil {
+		return nil, "", err
+	}
+	return decoded, string(indented), nil
+}
+
+// ExpectJSON checks that the body is semantically equal to the expected JSON, and returns the response.
+// The expected JSON is the document string or []byte, or the other value encoded in JSON.
+// The differences of the object key order and the whitespaces are ignored.
+func (res *HTTPResponse) ExpectJSON(expected interface{}) *HTTPResponse {
+	res.tb.Helper()
+	expectedValue, expectedJSON, err := normalizeJSON(expected)
+	if err != nil {
+		res.tb.Fatalf("malformed expected JSON: %s", err)
+		return res
+	}
+	actualValue, actualJSON, err := normalizeJSON(res.Body)
+	if err != nil {
+		res.errorf("malformed JSON body: %s", err)
+		return res
+	}
+	if !reflect.DeepEqual(expectedValue, actualValue) {
+		res.errorf("unexpected JSON body:\n%s", diffLines(expectedJSON, actualJSON, colorEnabled(res.tb)))
+	}
+	return res
+}
@@ -0,0 +1,147 @@
+package goassert
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestServeHTTP(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "req-42")
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"host":  r.Host,
+			"query": r.URL.Query().Get("q"),
+			"token": r.Header.Get("Authorization"),
+			"body":  string(body),
+		})
+	})
+	// test1: Test helper registration
+	tb1 := NewHookedTestingTB("test1")
+	ServeHTTP(tb1, handler, NewHTTPRequest("POST", "/")).ExpectStatus(http.StatusOK)
+	// NOTICE: We cannot identify the location of any helper exactly, because of go cover tool inserts some code into source code files.
+	if len(tb1.Helpers) != 2 {
+		t.Fatalf("test1: unexpected Helpers: %#v", tb1.Helpers)
+	}
+	// test2: only normal uses
+	tb2 := NewHookedTestingTB("test2")
+	res := ServeHTTP(tb2, handler, NewHTTPRequest("POST", "/search").WithQuery("q", "go").WithHeader("Authorization", "secret").WithJSON([]int{1, 2}))
+	res.ExpectStatus(http.StatusOK).
+		ExpectHeader("content-type", "application/json").
+		ExpectHeaderMatches("X-Request-Id", `^req-\d+$`).
+		ExpectBody(`{"body":"[1,2]","host":"example.com","query":"go","token":"secret"}` + "\n").
+		ExpectBodyMatches(`"query":"go"`).
+		ExpectJSON(`{"token": "secret", "query": "go", "host": "example.com", "body": "[1,2]"}`).
+		ExpectJSON(map[string]string{"body": "[1,2]", "host": "example.com", "query": "go", "token": "secret"})
+	if tb2.Failed() {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	if string(res.Body) != `{"body":"[1,2]","host":"example.com","query":"go","token":"secret"}`+"\n" {
+		t.Fatalf("test2: unexpected Body: %q", res.Body)
+	}
+	// test3: error cases followed by fatal exit cases
+	tb3 := NewHookedTestingTB("test3")
+	ServeHTTP(tb3, handler, NewHTTPRequest("GET", "/hello").WithBody("hi")).
+		ExpectStatus(http.StatusOK).
+		ExpectHeader("X-Request-Id", "req-0").
+		ExpectHeaderMatches("X-Request-Id", `^\d+$`).
+		ExpectBody("hello").
+		ExpectBodyMatches("hello").
+		ExpectJSON(`{}`)
+	for _, f := range []func(){
+		func() {
+			ServeHTTP(tb3, handler, NewHTTPRequest("GET", "/").WithJSON(func() {}))
+		},
+		func() {
+			ServeHTTP(tb3, handler, NewHTTPRequest("GET", "/%zz"))
+		},
+		func() {
+			ServeHTTP(tb3, handler, NewHTTPRequest("POST", "/")).ExpectJSON("{")
+		},
+	} {
+		func() {
+			defer func() {
+				recover()
+			}()
+			f()
+		}()
+	}
+	if !tb3.Failed() {
+		t.Fatalf("test3: unexpected Failed() == false")
+	}
+	dump := "\n\nrequest:\nGET /hello HTTP/1.1\r\nHost: example.com\r\n\r\nhi\n\nresponse:\nHTTP/1.1 405 Method Not Allowed\r\nConnection: close\r\nContent-Type: application/json\r\nX-Request-Id: req-42\r\n\r\n"
+	for i, message := range []string{
+		"ERROR: expected status 200 OK, but got 405 Method Not Allowed" + dump,
+		"ERROR: expected header X-Request-Id \"req-0\", but got [\"req-42\"]" + dump,
+		"ERROR: expected header X-Request-Id pattern \"^\\\\d+$\", but got \"req-42\"" + dump,
+		"ERROR: unexpected body:\n- hello\n+ " + dump,
+		"ERROR: expected body pattern \"hello\", but got body \"\"" + dump,
+		"ERROR: malformed JSON body: unexpected end of JSON input" + dump,
+		"FATAL: cannot build request: json: unsupported type: func()",
+		"FATAL: cannot build request: parse \"/%zz\": invalid URL escape \"%zz\"",
+		"FATAL: malformed expected JSON: unexpected end of JSON input",
+	} {
+		if i >= len(tb3.Messages) || tb3.Messages[i] != message {
+			t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+		}
+	}
+	// test4: JSON difference
+	tb4 := NewHookedTestingTB("test4")
+	ServeHTTP(tb4, handler, NewHTTPRequest("POST", "/")).ExpectJSON(map[string]interface{}{"body": "", "host": "example.com", "query": "", "token": "t"})
+	if !(len(tb4.Messages) == 1 && strings.HasPrefix(tb4.Messages[0], "ERROR: unexpected JSON body:\n  {\n    \"body\": \"\",\n    \"host\": \"example.com\",\n    \"query\": \"\",\n-   \"token\": \"t\"\n+   \"token\": \"\"\n  }\n\nrequest:\n")) {
+		t.Fatalf("test4: unexpected Messages: %#v", tb4.Messages)
+	}
+}
+
+func TestHTTPRequestBuild(t *testing.T) {
+	r, err := NewHTTPRequest("PUT", "http://example.org/a?x=1").WithQuery("y", "2").WithHeader("Host", "api.example.org").build()
+	if err != nil {
+		t.Fatalf("test: unexpected error: %s", err)
+	}
+	if got := fmt.Sprintf("%s %s %s %s", r.Method, r.Host, r.RequestURI, r.URL.Query()); got != "PUT api.example.org /a?x=1&y=2 map[x:[1] y:[2]]" {
+		t.Fatalf("test: unexpected request: %s", got)
+	}
+	if !reflect.DeepEqual(r.Header, http.Header{"Host": {"api.example.org"}}) {
+		t.Fatalf("test: unexpected Header: %#v", r.Header)
+	}
+}
+
+func TestNormalizeJSON(t *testing.T) {
+	// test1: numbers compared exactly
+	for i, testcase := range []struct {
+		x, y  string
+		equal bool
+	}{
+		{`9007199254740993`, `9007199254740992`, false},
+		{`[1, 1.0, 1e0, 10E-1]`, `[1, 1, 1, 1]`, true},
+		{`{"a": 0.50}`, `{"a": 5e-1}`, true},
+		{`0.1`, `0.10000000000000001`, false},
+		{`1e100000`, `1e100000`, true},
+	} {
+		x, _, err := normalizeJSON(testcase.x)
+		if err != nil {
+			t.Fatalf("test1 #%d: unexpected error: %s", i, err)
+		}
+		y, _, err := normalizeJSON(testcase.y)
+		if err != nil {
+			t.Fatalf("test1 #%d: unexpected error: %s", i, err)
+		}
+		if reflect.DeepEqual(x, y) != testcase.equal {
+			t.Fatalf("test1 #%d: unexpected equality of %#v and %#v", i, x, y)
+		}
+	}
+	// test2: canonical texts
+	if _, indented, err := normalizeJSON(`[1.0e2, -0.250, 3]`); err != nil || indented != "[\n  100,\n  -0.25,\n  3\n]" {
+		t.Fatalf("test2: unexpected encoding: %q, %v", indented, err)
+	}
+}
@@ -0,0 +1,448 @@
+package goassert
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+)
+
+// markupNode is a node of the normalized XML or HTML document tree.
+// The text node has the empty name.
+type markupNode struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	text     string
+	children []*markupNode
+}
+
+// isText returns true if the node is a text node.
+func (node *markupNode) isText() bool {
+	return node.name.Local == ""
+}
+
+// String returns the short description of the node.
+func (node *markupNode) String() string {
+	if node.isText() {
+		return fmt.Sprintf("text %q", node.text)
+	}
+	if node.name.Space != "" {
+		return fmt.Sprintf("element <%s xmlns=%q>", node.name.Local, node.name.Space)
+	}
+	return fmt.Sprintf("element <%s>", node.name.Local)
+}
+
+// appendText appends the character data to the children of the node.
+// The adjacent character data are merged into one text node.
+func (node *markupNode) appendText(text string) {
+	if n := len(node.children); n > 0 && node.children[n-1].isText() {
+		node.children[n-1].text += text
+		return
+	}
+	node.children = append(node.children, &markupNode{text: text})
+}
+
+// normalize collapses the whitespaces in the text nodes, drops the empty text nodes and sorts the attributes recursively.
+// The text nodes in the elements of preserved or their descendants are kept as they are if preserve is true.
+func (node *markupNode) normalize(preserved map[string]bool, preserve bool) {
+	sort.Slice(node.attrs, func(i, j int) bool {
+		if node.attrs[i].Name.Space != node.attrs[j].Name.Space {
+			return node.attrs[i].Name.Space < node.attrs[j].Name.Space
+		}
+		return node.attrs[i].Name.Local < node.attrs[j].Name.Local
+	})
+	preserve = preserve || preserved[node.name.Local]
+	children := node.children[:0]
+	for _, child := range node.children {
+		if child.isText() {
+			if !preserve {
+				child.text = strings.Join(strings.Fields(child.text), " ")
+			}
+			if child.text == "" {
+				continue
+			}
+		}
+		child.normalize(preserved, preserve)
+		children = append(children, child)
+	}
+	node.children = children
+}
+
+// parseXML parses the XML document into the normalized tree.
+// The namespace prefixes are resolved into the namespace URLs, and the namespace declarations are dropped.
+func parseXML(src string) (*markupNode, error) {
+	decoder := xml.NewDecoder(strings.NewReader(src))
+	root := &markupNode{}
+	stack := []*markupNode{root}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		parent := stack[len(stack)-1]
+		switch token := token.(type) {
+		case xml.StartElement:
+			node := &markupNode{name: token.Name}
+			for _, attr := range token.Attr {
+				if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+					continue
+				}
+				node.attrs = append(node.attrs, attr)
+			}
+			parent.children = append(parent.children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			parent.appendText(string(token))
+		}
+	}
+	root.normalize(nil, false)
+	return root, nil
+}
+
+// htmlVoidElements is the set of the HTML elements which have no content.
+var htmlVoidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true, "input": true,
+	"link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// htmlRawTextElements is the set of the HTML elements whose content is not parsed as markup.
+// The value indicates whether the character references in the content are unescaped or not.
+var htmlRawTextElements = map[string]bool{
+	"script": false, "style": false, "textarea": true, "title": true,
+}
+
+// htmlPreformattedElements is the set of the HTML elements whose whitespaces are significant.
+var htmlPreformattedElements = map[string]bool{
+	"pre": true, "textarea": true, "script": true, "style": true,
+}
+
+// htmlImpliedEndElements maps the HTML element to the elements whose start tag implicitly closes it.
+var htmlImpliedEndElements = map[string][]string{
+	"dd":     {"dd", "dt"},
+	"dt":     {"dd", "dt"},
+	"li":     {"li"},
+	"option": {"option", "optgroup"},
+	"p": {"address", "article", "aside", "blockquote", "div", "dl", "fieldset", "footer", "form", "h1", "h2", "h3",
+		"h4", "h5", "h6", "header", "hr", "main", "nav", "ol", "p", "pre", "section", "table", "ul"},
+	"td": {"td", "th", "tr"},
+	"th": {"td", "th", "tr"},
+	"tr": {"tr"},
+}
+
+// htmlTokenizer is a minimal tokenizer of HTML documents.
+// It does not validate the document, but tolerates the common omissions in HTML.
+type htmlTokenizer struct {
+	src string
+	pos int
+	// rawText is the pending content of the last raw text element.
+	rawText *xml.CharData
+}
+
+// skipSpaces skips the whitespaces.
+func (tokenizer *htmlTokenizer) skipSpaces() {
+	for tokenizer.pos < len(tokenizer.src) && strings.IndexByte(" \t\n\r\f", tokenizer.src[tokenizer.pos]) >= 0 {
+		tokenizer.pos++
+	}
+}
+
+// readName reads the tag or attribute name in lower case.
+func (tokenizer *htmlTokenizer) readName() string {
+	start := tokenizer.pos
+	for tokenizer.pos < len(tokenizer.src) && strings.IndexByte(" \t\n\r\f/>=", tokenizer.src[tokenizer.pos]) < 0 {
+		tokenizer.pos++
+	}
+	return strings.ToLower(tokenizer.src[start:tokenizer.pos])
+}
+
+// readAttrValue reads the quoted or unquoted attribute value.
+func (tokenizer *htmlTokenizer) readAttrValue() (string, error) {
+	if tokenizer.pos < len(tokenizer.src) && (tokenizer.src[tokenizer.pos] == '"' || tokenizer.src[tokenizer.pos] == '\'') {
+		quote := tokenizer.src[tokenizer.pos]
+		end := strings.IndexByte(tokenizer.src[tokenizer.pos+1:], quote)
+		if end < 0 {
+			return "", fmt.Errorf("HTML syntax error at offset %d: unterminated attribute value", tokenizer.pos)
+		}
+		value := tokenizer.src[tokenizer.pos+1 : tokenizer.pos+1+end]
+		tokenizer.pos += end + 2
+		return html.UnescapeString(value), nil
+	}
+	start := tokenizer.pos
+	for tokenizer.pos < len(tokenizer.src) && strings.IndexByte(" \t\n\r\f>", tokenizer.src[tokenizer.pos]) < 0 {
+		tokenizer.pos++
+	}
+	return html.UnescapeString(tokenizer.src[start:tokenizer.pos]), nil
+}
+
+// next returns the next token as xml.Token, or io.EOF at the end of the document.
+// The comments, doctypes and processing instructions are returned as xml.Comment.
+func (tokenizer *htmlTokenizer) next() (xml.Token, error) {
+	if rawText := tokenizer.rawText; rawText != nil {
+		tokenizer.rawText = nil
+		return *rawText, nil
+	}
+	src := tokenizer.src
+	if tokenizer.pos >= len(src) {
+		return nil, io.EOF
+	}
+	if src[tokenizer.pos] != '<' || tokenizer.pos+1 >= len(src) || !strings.ContainsRune("!?/abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ", rune(src[tokenizer.pos+1])) {
+		start := tokenizer.pos
+		end := strings.IndexByte(src[start+1:], '<')
+		if end < 0 {
+			tokenizer.pos = len(src)
+		} else {
+			tokenizer.pos = start + 1 + end
+		}
+		return xml.CharData(html.UnescapeString(src[start:tokenizer.pos])), nil
+	}
+	switch {
+	case strings.HasPrefix(src[tokenizer.pos:], "<!--"):
+		end := strings.Index(src[tokenizer.pos+4:], "-->")
+		if end < 0 {
+			return nil, fmt.Errorf("HTML syntax error at offset %d: unterminated comment", tokenizer.pos)
+		}
+		comment := src[tokenizer.pos+4 : tokenizer.pos+4+end]
+		tokenizer.pos += 4 + end + 3
+		return xml.Comment(comment), nil
+	case src[tokenizer.pos+1] == '!' || src[tokenizer.pos+1] == '?':
+		end := strings.IndexByte(src[tokenizer.pos:], '>')
+		if end < 0 {
+			return nil, fmt.Errorf("HTML syntax error at offset %d: unterminated declaration", tokenizer.pos)
+		}
+		comment := src[tokenizer.pos+2 : tokenizer.pos+end]
+		tokenizer.pos += end + 1
+		return xml.Comment(comment), nil
+	case src[tokenizer.pos+1] == '/':
+		tokenizer.pos += 2
+		name := tokenizer.readName()
+		end := strings.IndexByte(src[tokenizer.pos:], '>')
+		if end < 0 {
+			return nil, fmt.Errorf("HTML syntax error at offset %d: unterminated end tag </%s", tokenizer.pos, name)
+		}
+		tokenizer.pos += end + 1
+		return xml.EndElement{Name: xml.Name{Local: name}}, nil
+	}
+	tokenizer.pos++
+	start := xml.StartElement{Name: xml.Name{Local: tokenizer.readName()}}
+	for {
+		tokenizer.skipSpaces()
+		if tokenizer.pos >= len(src) {
+			return nil, fmt.Errorf("HTML syntax error at offset %d: unterminated start tag <%s", tokenizer.pos, start.Name.Local)
+		}
+		if src[tokenizer.pos] == '>' {
+			tokenizer.pos++
+			break
+		}
+		if strings.HasPrefix(src[tokenizer.pos:], "/>") {
+			tokenizer.pos += 2
+			break
+		}
+		if src[tokenizer.pos] == '/' {
+			tokenizer.pos++
+			continue
+		}
+		attr := xml.Attr{Name: xml.Name{Local: tokenizer.readName()}}
+		tokenizer.skipSpaces()
+		if tokenizer.pos < len(src) && src[tokenizer.pos] == '=' {
+			tokenizer.pos++
+			tokenizer.skipSpaces()
+			value, err := tokenizer.readAttrValue()
+			if err != nil {
+				return nil, err
+			}
+			attr.Value = value
+		}
+		start.Attr = append(start.Attr, attr)
+	}
+	if escapable, ok := htmlRawTextElements[start.Name.Local]; ok {
+		end := strings.Index(strings.ToLower(src[tokenizer.pos:]), "</"+start.Name.Local)
+		if end < 0 {
+			end = len(src) - tokenizer.pos
+		}
+		rawText := src[tokenizer.pos : tokenizer.pos+end]
+		if escapable {
+			rawText = html.UnescapeString(rawText)
+		}
+		charData := xml.CharData(rawText)
+		tokenizer.rawText = &charData
+		tokenizer.pos += end
+	}
+	return start, nil
+}
+
+// parseHTML parses the HTML document into the normalized tree.
+// The element and attribute names are case-insensitive, and the omitted end tags are complemented.
+func parseHTML(src string) (*markupNode, error) {
+	tokenizer := &htmlTokenizer{src: src}
+	root := &markupNode{}
+	stack := []*markupNode{root}
+	for {
+		token, err := tokenizer.next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			for len(stack) > 1 {
+				implied := false
+				for _, name := range htmlImpliedEndElements[stack[len(stack)-1].name.Local] {
+					if name == token.Name.Local {
+						implied = true
+						break
+					}
+				}
+				if !implied {
+					break
+				}
+				stack = stack[:len(stack)-1]
+			}
+			parent := stack[len(stack)-1]
+			node := &markupNode{name: token.Name, attrs: token.Attr}
+			parent.children = append(parent.children, node)
+			if !htmlVoidElements[token.Name.Local] {
+				stack = append(stack, node)
+			}
+		case xml.EndElement:
+			// Close the innermost open element with the same name, or ignore the stray end tag.
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].name.Local == token.Name.Local {
+					stack = stack[:i]
+					break
+				}
+			}
+		case xml.CharData:
+			stack[len(stack)-1].appendText(string(token))
+		}
+	}
+	root.normalize(htmlPreformattedElements, false)
+	return root, nil
+}
+
+// markupChildPath returns the XPath-like location of the i-th child of the node at the path.
+func markupChildPath(path string, node *markupNode, i int) string {
+	child := node.children[i]
+	step := "text()"
+	if !child.isText() {
+		step = child.name.Local
+	}
+	index, count := 0, 0
+	for j, sibling := range node.children {
+		if sibling.isText() == child.isText() && sibling.name == child.name {
+			count++
+			if j <= i {
+				index++
+			}
+		}
+	}
+	if count > 1 {
+		step = fmt.Sprintf("%s[%d]", step, index)
+	}
+	return path + "/" + step
+}
+
+// compareMarkup compares the expected and actual nodes at the path, and returns the description of the first difference.
+// compareMarkup returns the empty string if the both nodes are equal.
+func compareMarkup(path string, expected, actual *markupNode) string {
+	if path == "" {
+		path = "/"
+	}
+	if expected.isText() != actual.isText() || expected.name != actual.name || expected.text != actual.text {
+		return fmt.Sprintf("at %s, expected %s, but got %s", path, expected, actual)
+	}
+	for _, expectedAttr := range expected.attrs {
+		attrPath := strings.TrimSuffix(path, "/") + "/@" + expectedAttr.Name.Local
+		found := false
+		for _, actualAttr := range actual.attrs {
+			if actualAttr.Name == expectedAttr.Name {
+				if actualAttr.Value != expectedAttr.Value {
+					return fmt.Sprintf("at %s, expected attribute value %q, but got %q", attrPath, expectedAttr.Value, actualAttr.Value)
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Sprintf("at %s, expected attribute value %q, but got no attribute", attrPath, expectedAttr.Value)
+		}
+	}
+	for _, actualAttr := range actual.attrs {
+		found := false
+		for _, expectedAttr := range expected.attrs {
+			if expectedAttr.Name == actualAttr.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			attrPath := strings.TrimSuffix(path, "/") + "/@" + actualAttr.Name.Local
+			return fmt.Sprintf("at %s, expected no attribute, but got attribute value %q", attrPath, actualAttr.Value)
+		}
+	}
+	parentPath := strings.TrimSuffix(path, "/")
+	for i := 0; i < len(expected.children) && i < len(actual.children); i++ {
+		if diff := compareMarkup(markupChildPath(parentPath, expected, i), expected.children[i], actual.children[i]); diff != "" {
+			return diff
+		}
+	}
+	if n := len(actual.children); n < len(expected.children) {
+		return fmt.Sprintf("at %s, expected %s, but got no node", markupChildPath(parentPath, expected, n), expected.children[n])
+	} else if n := len(expected.children); n < len(actual.children) {
+		return fmt.Sprintf("at %s, expected no node, but got %s", markupChildPath(parentPath, actual, n), actual.children[n])
+	}
+	return ""
+}
+
+// markupEqual is the implementation of XMLEq and HTMLEq.
+func (assert *Assert) markupEqual(kind string, parse func(string) (*markupNode, error), actual interface{}) {
+	assert.tb.Helper()
+	if len(assert.expected) != 1 {
+		assert.tb.Fatalf("the number of the expected %s documents must be one", kind)
+		return
+	}
+	var docs [2]*markupNode
+	for i, v := range []interface{}{assert.expected[0], actual} {
+		name := []string{"expected", "actual"}[i]
+		var src string
+		switch v := v.(type) {
+		case string:
+			src = v
+		case []byte:
+			src = string(v)
+		default:
+			assert.tb.Fatalf("%s %s document must be string or []byte, but got %T", name, kind, v)
+			return
+		}
+		doc, err := parse(src)
+		if err != nil {
+			assert.tb.Fatalf("malformed %s %s document: %s", name, kind, err)
+			return
+		}
+		docs[i] = doc
+	}
+	if diff := compareMarkup("", docs[0], docs[1]); diff != "" {
+		assert.tb.Errorf("%s", diff)
+	}
+}
+
+// XMLEq checks that the given actual XML document is semantically equal to the expected XML document.
+// The expected values must be one XML document, and the documents must be string or []byte.
+// The whitespaces, the attribute order and the namespace prefixes are ignored, and so are the comments and the processing instructions.
+func (assert *Assert) XMLEq(actual interface{}) {
+	assert.tb.Helper()
+	assert.markupEqual("XML", parseXML, actual)
+}
+
+// HTMLEq checks that the given actual HTML document is semantically equal to the expected HTML document.
+// The expected values must be one HTML document, and the documents must be string or []byte.
+// The whitespaces except for the ones in pre, textarea, script and style, the attribute order and the case of names are ignored, and so are the comments and the doctypes.
+func (assert *Assert) HTMLEq(actual interface{}) {
+	assert.tb.Helper()
+	assert.markupEqual("HTML", parseHTML, actual)
+}
@@ -0,0 +1,134 @@
+package goassert
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAssertXMLEq(t *testing.T) {
+	// test1: Test helper registration
+	tb1 := NewHookedTestingTB("test1")
+	New(tb1, "<a/>").XMLEq("<a/>")
+	// NOTICE: We cannot identify the location of any helper exactly, because of go cover tool inserts some code into source code files.
+	if len(tb1.Helpers) != 2 {
+		t.Fatalf("test1: unexpected Helpers: %#v", tb1.Helpers)
+	}
+	// test2: only normal uses
+	tb2 := NewHookedTestingTB("test2")
+	New(tb2, "<a/>").XMLEq([]byte("<a></a>"))
+	New(tb2, `<?xml version="1.0"?><a x="1" y="2"><b>hello  world</b></a>`).XMLEq("<a y='2' x='1'>\n  <!-- comment -->\n  <b>\n    hello\n    world\n  </b>\n</a>")
+	New(tb2, `<s:Envelope xmlns:s="urn:soap"><s:Body><m:Price xmlns:m="urn:m" m:currency="JPY">100</m:Price></s:Body></s:Envelope>`).XMLEq(
+		`<Envelope xmlns="urn:soap"><Body><p:Price xmlns:p="urn:m" p:currency="JPY">100</p:Price></Body></Envelope>`)
+	if tb2.Failed() {
+		t.Fatalf("test2: unexpected Failed() == true")
+	}
+	if len(tb2.Messages) != 0 {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	// test3: error cases
+	tb3 := NewHookedTestingTB("test3")
+	New(tb3, "<a><b/></a>").XMLEq("<a><c/></a>")
+	New(tb3, "<a><b>1</b><b>2</b></a>").XMLEq("<a><b>1</b><b>3</b></a>")
+	New(tb3, `<a><b x="1"/></a>`).XMLEq(`<a><b x="2"/></a>`)
+	New(tb3, `<a x="1"/>`).XMLEq(`<a/>`)
+	New(tb3, `<a/>`).XMLEq(`<a y="1"/>`)
+	New(tb3, `<a><b/><c/></a>`).XMLEq(`<a><b/></a>`)
+	New(tb3, `<a><b/></a>`).XMLEq(`<a><b/>text</a>`)
+	New(tb3, `<a xmlns="urn:x"/>`).XMLEq(`<a xmlns="urn:y"/>`)
+	if !tb3.Failed() {
+		t.Fatalf("test3: unexpected Failed() == false")
+	}
+	if !reflect.DeepEqual(tb3.Messages, []string{
+		"ERROR: at /a/b, expected element <b>, but got element <c>",
+		"ERROR: at /a/b[2]/text(), expected text \"2\", but got text \"3\"",
+		"ERROR: at /a/b/@x, expected attribute value \"1\", but got \"2\"",
+		"ERROR: at /a/@x, expected attribute value \"1\", but got no attribute",
+		"ERROR: at /a/@y, expected no attribute, but got attribute value \"1\"",
+		"ERROR: at /a/c, expected element <c>, but got no node",
+		"ERROR: at /a/text(), expected no node, but got text \"text\"",
+		"ERROR: at /a, expected element <a xmlns=\"urn:x\">, but got element <a xmlns=\"urn:y\">",
+	}) {
+		t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+	}
+	// test4: fatal exit cases
+	for i, testcase := range []struct {
+		expected []interface{}
+		actual   interface{}
+		message  string
+	}{
+		{[]interface{}{}, "<a/>", "FATAL: the number of the expected XML documents must be one"},
+		{[]interface{}{1}, "<a/>", "FATAL: expected XML document must be string or []byte, but got int"},
+		{[]interface{}{"<a/>"}, 1, "FATAL: actual XML document must be string or []byte, but got int"},
+		{[]interface{}{"<a>"}, "<a/>", "FATAL: malformed expected XML document: XML syntax error on line 1: unexpected EOF"},
+		{[]interface{}{"<a/>"}, "<a></b>", "FATAL: malformed actual XML document: XML syntax error on line 1: element <a> closed by </b>"},
+	} {
+		tb4 := NewHookedTestingTB("test4")
+		func() {
+			defer func() {
+				recover()
+			}()
+			New(tb4, testcase.expected...).XMLEq(testcase.actual)
+		}()
+		if !tb4.Failed() {
+			t.Fatalf("test4 #%d: unexpected Failed() == false", i)
+		}
+		if !reflect.DeepEqual(tb4.Messages, []string{testcase.message}) {
+			t.Fatalf("test4 #%d: unexpected Messages: %#v", i, tb4.Messages)
+		}
+	}
+}
+
+func TestAssertHTMLEq(t *testing.T) {
+	// test1: Test helper registration
+	tb1 := NewHookedTestingTB("test1")
+	New(tb1, "<p>hello</p>").HTMLEq("<p>hello</p>")
+	// NOTICE: We cannot identify the location of any helper exactly, because of go cover tool inserts some code into source code files.
+	if len(tb1.Helpers) != 2 {
+		t.Fatalf("test1: unexpected Helpers: %#v", tb1.Helpers)
+	}
+	// test2: only normal uses
+	tb2 := NewHookedTestingTB("test2")
+	New(tb2, `<!DOCTYPE html><html><body><p class="x" id="y">hello&nbsp;world<br></p><ul><li>a</li><li>b</li></ul></body></html>`).HTMLEq(
+		"<HTML>\n<BODY>\n<!-- comment -->\n<P ID=y class='x'>hello&#160;world<br/>\n<UL><LI>a\n<LI>b\n</UL>\n</BODY>")
+	New(tb2, "<p>a  b</p><pre>a  b</pre>").HTMLEq("<p>a b</p>\n<pre>a  b</pre>")
+	New(tb2, `<input disabled type="checkbox"><script>if (a < b) {}</script>`).HTMLEq([]byte(`<input type=checkbox disabled><script>if (a < b) {}</script>`))
+	if tb2.Failed() {
+		t.Fatalf("test2: unexpected Failed() == true")
+	}
+	if len(tb2.Messages) != 0 {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	// test3: error cases
+	tb3 := NewHookedTestingTB("test3")
+	New(tb3, "<ul><li>a<li>b</ul>").HTMLEq("<ul><li>a<li>c</ul>")
+	New(tb3, `<div><input type="text"></div>`).HTMLEq(`<div><input type="password"></div>`)
+	New(tb3, "<div><p>a</p></div>").HTMLEq("<div><span>a</span></div>")
+	New(tb3, "<pre>a  <b>b</b></pre>").HTMLEq("<pre>a <b>b</b></pre>")
+	New(tb3, "<textarea>a\n</textarea>").HTMLEq("<textarea>a</textarea>")
+	if !tb3.Failed() {
+		t.Fatalf("test3: unexpected Failed() == false")
+	}
+	if !reflect.DeepEqual(tb3.Messages, []string{
+		"ERROR: at /ul/li[2]/text(), expected text \"b\", but got text \"c\"",
+		"ERROR: at /div/input/@type, expected attribute value \"text\", but got \"password\"",
+		"ERROR: at /div/p, expected element <p>, but got element <span>",
+		"ERROR: at /pre/text(), expected text \"a  \", but got text \"a \"",
+		"ERROR: at /textarea/text(), expected text \"a\\n\", but got text \"a\"",
+	}) {
+		t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+	}
+	// test4: fatal exit case
+	tb4 := NewHookedTestingTB("test4")
+	func() {
+		defer func() {
+			recover()
+		}()
+		New(tb4, "<p>hello</p>").HTMLEq(`<p class="x>hello</p>`)
+	}()
+	if !tb4.Failed() {
+		t.Fatalf("test4: unexpected Failed() == false")
+	}
+	if !reflect.DeepEqual(tb4.Messages, []string{"FATAL: malformed actual HTML document: HTML syntax error at offset 9: unterminated attribute value"}) {
+		t.Fatalf("test4: unexpected Messages: %#v", tb4.Messages)
+	}
+}
@@ -0,0 +1,310 @@
+package goassert
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// mockAnything is the type of Anything.
+type mockAnything struct{}
+
+// Anything is the argument matcher matching any value.
+var Anything interface{} = mockAnything{}
+
+// ArgMatcher is the argument matcher satisfying the predicate.
+type ArgMatcher struct {
+	description string
+	match       func(v interface{}) bool
+}
+
+// ArgThat returns a new ArgMatcher with the description and the predicate.
+func ArgThat(description string, match func(v interface{}) bool) *ArgMatcher {
+	return &ArgMatcher{
+		description: description,
+		match:       match,
+	}
+}
+
+// ArgOfType returns a new ArgMatcher matching any value of the type formatted by %T, e.g. "*bytes.Buffer".
+func ArgOfType(typeName string) *ArgMatcher {
+	return ArgThat(fmt.Sprintf("any %s", typeName), func(v interface{}) bool {
+		return fmt.Sprintf("%T", v) == typeName
+	})
+}
+
+// formatArg returns the formatted expected argument, which is a value or a matcher.
+func formatArg(arg interface{}) string {
+	switch arg := arg.(type) {
+	case mockAnything:
+		return "Anything"
+	case *ArgMatcher:
+		return fmt.Sprintf("<%s>", arg.description)
+	}
+	return formatValue(arg)
+}
+
+// formatCall returns the formatted call of the method with the arguments.
+func formatCall(method string, args []interface{}) string {
+	strs := make([]string, len(args))
+	for i, arg := range args {
+		strs[i] = formatArg(arg)
+	}
+	return fmt.Sprintf("%s(%s)", method, strings.Join(strs, ", "))
+}
+
+// MockCall is the expectation of the calls of the mocked method.
+type MockCall struct {
+	mock     *Mock
+	method   string
+	args     []interface{}
+	results  []interface{}
+	minCalls int
+	maxCalls int
+	calls    int
+	after    []*MockCall
+	run      func(args []interface{})
+}
+
+// String returns the formatted expected call.
+func (call *MockCall) String() string {
+	return formatCall(call.method, call.args)
+}
+
+// Return sets the values returned by the call, and returns the call.
+func (call *MockCall) Return(results ...interface{}) *MockCall {
+	call.mock.mu.Lock()
+	defer call.mock.mu.Unlock()
+	call.results = results
+	return call
+}
+
+// Run sets the function called with the actual arguments before returning, and returns the call.
+func (call *MockCall) Run(f func(args []interface{})) *MockCall {
+	call.mock.mu.Lock()
+	defer call.mock.mu.Unlock()
+	call.run = f
+	return call
+}
+
+// Times expects the call to be made exactly n times, and returns the call.
+// The call is expected to be made at least once by default.
+func (call *MockCall) Times(n int) *MockCall {
+	call.mock.mu.Lock()
+	defer call.mock.mu.Unlock()
+	call.minCalls, call.maxCalls = n, n
+	return call
+}
+
+// Once expects the call to be made exactly once, and returns the call.
+func (call *MockCall) Once() *MockCall {
+	return call.Times(1)
+}
+
+// AnyTimes expects the call to be made any number of times including zero, and returns the call.
+func (call *MockCall) AnyTimes() *MockCall {
+	call.mock.mu.Lock()
+	defer call.mock.mu.Unlock()
+	call.minCalls, call.maxCalls = 0, -1
+	return call
+}
+
+// After expects the call to be made after the calls are made, and returns the call.
+func (call *MockCall) After(calls ...*MockCall) *MockCall {
+	call.mock.mu.Lock()
+	defer call.mock.mu.Unlock()
+	call.after = append(call.after, calls...)
+	return call
+}
+
+// mismatch returns the description of the first mismatched argument, or the empty string if the arguments match.
+func (call *MockCall) mismatch(args []interface{}) string {
+	if len(call.args) != len(args) {
+		return fmt.Sprintf("expected %d argument(s), but got %d argument(s)", len(call.args), len(args))
+	}
+	for i, expected := range call.args {
+		matched := false
+		switch expected := expected.(type) {
+		case mockAnything:
+			matched = true
+		case *ArgMatcher:
+			matched = expected.match(args[i])
+		default:
+			matched = deepEqual(expected, args[i])
+		}
+		if !matched {
+			return fmt.Sprintf("at #%d argument, expected %s, but got %s", i, formatArg(expected), formatArg(args[i]))
+		}
+	}
+	return ""
+}
+
+// MockResults is the values returned by the mocked call.
+type MockResults []interface{}
+
+// Get returns the i-th value.
+func (results MockResults) Get(i int) interface{} {
+	if i >= len(results) {
+		panic(fmt.Sprintf("goassert: the mocked call returns %d value(s), but #%d value is requested", len(results), i))
+	}
+	return results[i]
+}
+
+// Error returns the i-th value as error, which may be nil.
+func (results MockResults) Error(i int) error {
+	if v := results.Get(i); v != nil {
+		return v.(error)
+	}
+	return nil
+}
+
+// Mock is the recorder of the calls of the mocked methods, which is embedded in the fake objects.
+// The mocked methods record their calls by Called, and the expectations of the calls are set by On.
+// The zero value is ready to use after Init.
+type Mock struct {
+	mu           sync.Mutex
+	tb           TestingTB
+	expectations []*MockCall
+}
+
+// Init sets the testing context, and registers Verify as its cleanup.
+func (m *Mock) Init(tb TestingTB) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tb = tb
+	tb.Cleanup(m.Verify)
+}
+
+// testingTB returns the testing context, which must be set by Init.
+func (m *Mock) testingTB() TestingTB {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.tb == nil {
+		panic("goassert: Mock is used before Init")
+	}
+	return m.tb
+}
+
+// On expects the call of the method with the arguments, and returns the call.
+// Each argument is the expected value compared as Assert.Equal does, Anything or *ArgMatcher.
+func (m *Mock) On(method string, args ...interface{}) *MockCall {
+	call := &MockCall{
+		mock:     m,
+		method:   method,
+		args:     args,
+		minCalls: 1,
+		maxCalls: -1,
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expectations = append(m.expectations, call)
+	return call
+}
+
+// isClosureName returns true if the last element of the function name is the one of the closures, e.g. func1, gowrap1 or deferwrap1.
+func isClosureName(name string) bool {
+	for _, prefix := range []string{"func", "gowrap", "deferwrap"} {
+		name = strings.TrimPrefix(name, prefix)
+	}
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Called records the call of the calling method with the arguments, and returns the values of the matched expectation.
+// The unexpected call is reported with the nearest expectations, and fails the test immediately.
+// The method name is identified from the caller, so Called must be called directly in the mocked method.
+// Called fails the test if it is called in the closure, including the deferred function and the goroutine; use MethodCalled there.
+func (m *Mock) Called(args ...interface{}) MockResults {
+	tb := m.testingTB()
+	tb.Helper()
+	pc, _, _, ok := runtime.Caller(1)
+	if !ok {
+		tb.Fatalf("cannot identify the mocked method")
+		return nil
+	}
+	name := strings.TrimSuffix(runtime.FuncForPC(pc).Name(), "-fm")
+	if method := name[strings.LastIndex(name, ".")+1:]; !isClosureName(method) {
+		return m.MethodCalled(method, args...)
+	}
+	tb.Fatalf("cannot identify the mocked method from closure %s, use MethodCalled with the method name instead", name)
+	return nil
+}
+
+// MethodCalled records the call of the method with the arguments, and returns the values of the matched expectation.
+// See Called for details.
+func (m *Mock) MethodCalled(method string, args ...interface{}) MockResults {
+	tb := m.testingTB()
+	tb.Helper()
+	m.mu.Lock()
+	var matched, exhausted *MockCall
+	mismatches := []string{}
+	for _, call := range m.expectations {
+		if call.method != method {
+			continue
+		}
+		if mismatch := call.mismatch(args); mismatch != "" {
+			mismatches = append(mismatches, fmt.Sprintf("  %s: %s", call, mismatch))
+			continue
+		}
+		if call.maxCalls >= 0 && call.calls >= call.maxCalls {
+			if exhausted == nil {
+				exhausted = call
+			}
+			continue
+		}
+		matched = call
+		break
+	}
+	if matched == nil {
+		m.mu.Unlock()
+		switch {
+		case exhausted != nil:
+			tb.Fatalf("unexpected call %s: expected %d call(s), but got %d call(s)", formatCall(method, args), exhausted.maxCalls, exhausted.calls+1)
+		case len(mismatches) > 0:
+			tb.Fatalf("unexpected call %s, mismatched expectations:\n%s", formatCall(method, args), strings.Join(mismatches, "\n"))
+		default:
+			tb.Fatalf("unexpected call %s: no expectation of method %s", formatCall(method, args), method)
+		}
+		return nil
+	}
+	for _, before := range matched.after {
+		if before.calls == 0 {
+			m.mu.Unlock()
+			tb.Fatalf("unexpected call %s before call %s", formatCall(method, args), before)
+			return nil
+		}
+	}
+	matched.calls++
+	run, results := matched.run, matched.results
+	m.mu.Unlock()
+	if run != nil {
+		run(args)
+	}
+	return MockResults(results)
+}
+
+// Verify checks that all the expectations are met.
+// Verify is called automatically at the cleanup of the testing context set by Init.
+func (m *Mock) Verify() {
+	tb := m.testingTB()
+	tb.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, call := range m.expectations {
+		if call.calls < call.minCalls {
+			if call.minCalls == call.maxCalls {
+				tb.Errorf("unmet expectation %s: expected %d call(s), but got %d call(s)", call, call.minCalls, call.calls)
+			} else {
+				tb.Errorf("unmet expectation %s: expected at least %d call(s), but got %d call(s)", call, call.minCalls, call.calls)
+			}
+		}
+	}
+}
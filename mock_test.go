@@ -0,0 +1,130 @@
+package goassert
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeStore is the fake object for testing Mock.
+type fakeStore struct {
+	Mock
+}
+
+func (store *fakeStore) Get(key string) (string, error) {
+	results := store.Called(key)
+	return results.Get(0).(string), results.Error(1)
+}
+
+func (store *fakeStore) Put(key string, value interface{}) error {
+	return store.Called(key, value).Error(0)
+}
+
+func (store *fakeStore) Delete(key string) (err error) {
+	defer func() {
+		err = store.Called(key).Error(0)
+	}()
+	return nil
+}
+
+func TestMock(t *testing.T) {
+	// test1: only normal uses
+	tb1 := NewHookedTestingTB("test1")
+	store := &fakeStore{}
+	store.Init(tb1)
+	put := store.On("Put", "a", Anything).Return(nil).Once()
+	store.On("Get", "a").Return("1", nil).After(put)
+	store.On("Get", ArgThat("prefix b", func(v interface{}) bool { return strings.HasPrefix(v.(string), "b") })).Return("", errors.New("not found")).AnyTimes()
+	store.On("Put", ArgOfType("string"), ArgOfType("int")).Return(nil).Times(2)
+	recorded := []interface{}{}
+	store.On("Put", "c", Anything).Run(func(args []interface{}) {
+		recorded = append(recorded, args[1])
+	}).Return(nil)
+	New(tb1, nil).Equal(store.Put("a", 1.5))
+	New(tb1, "1", nil).Equal(store.Get("a"))
+	New(tb1, "1", nil).Equal(store.Get("a"))
+	New(tb1, "", errors.New("not found")).Equal(store.Get("bb"))
+	New(tb1, nil).Equal(store.Put("x", 1))
+	New(tb1, nil).Equal(store.Put("y", 2))
+	New(tb1, nil).Equal(store.Put("c", true))
+	tb1.DoCleanups()
+	if tb1.Failed() {
+		t.Fatalf("test1: unexpected Messages: %#v", tb1.Messages)
+	}
+	if !reflect.DeepEqual(recorded, []interface{}{true}) {
+		t.Fatalf("test1: unexpected recorded: %#v", recorded)
+	}
+	// test2: unmet expectations
+	tb2 := NewHookedTestingTB("test2")
+	store = &fakeStore{}
+	store.Init(tb2)
+	store.On("Get", "a").Return("1", nil)
+	store.On("Put", "a", 1).Return(nil).Times(2)
+	store.Put("a", 1)
+	tb2.DoCleanups()
+	if !reflect.DeepEqual(tb2.Messages, []string{
+		"ERROR: unmet expectation Get(\"a\" (string)): expected at least 1 call(s), but got 0 call(s)",
+		"ERROR: unmet expectation Put(\"a\" (string), 1 (int)): expected 2 call(s), but got 1 call(s)",
+	}) {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	// test3: fatal exit cases
+	tb3 := NewHookedTestingTB("test3")
+	store = &fakeStore{}
+	store.Init(tb3)
+	first := store.On("Put", "a", ArgOfType("int")).Return(nil).Once()
+	store.On("Put", "b", Anything, Anything)
+	store.On("Get", "b").Return("2", nil).After(first)
+	for _, f := range []func(){
+		func() { store.Put("a", 1) },
+		func() { store.Put("a", 2) },
+		func() { store.Put("a", "1") },
+		func() { store.Get("b") },
+		func() { store.MethodCalled("Delete", "a") },
+	} {
+		func() {
+			defer func() {
+				recover()
+			}()
+			f()
+		}()
+	}
+	if !reflect.DeepEqual(tb3.Messages, []string{
+		"FATAL: unexpected call Put(\"a\" (string), 2 (int)): expected 1 call(s), but got 2 call(s)",
+		"FATAL: unexpected call Put(\"a\" (string), \"1\" (string)), mismatched expectations:\n" +
+			"  Put(\"a\" (string), <any int>): at #1 argument, expected <any int>, but got \"1\" (string)\n" +
+			"  Put(\"b\" (string), Anything, Anything): expected 3 argument(s), but got 2 argument(s)",
+		"FATAL: unexpected call Delete(\"a\" (string)): no expectation of method Delete",
+	}) {
+		t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+	}
+	// test4: ordering
+	tb4 := NewHookedTestingTB("test4")
+	store = &fakeStore{}
+	store.Init(tb4)
+	store.On("Get", "b").Return("2", nil).After(store.On("Put", "b", 2))
+	func() {
+		defer func() {
+			recover()
+		}()
+		store.Get("b")
+	}()
+	if !reflect.DeepEqual(tb4.Messages, []string{"FATAL: unexpected call Get(\"b\" (string)) before call Put(\"b\" (string), 2 (int))"}) {
+		t.Fatalf("test4: unexpected Messages: %#v", tb4.Messages)
+	}
+	// test5: Called in the closure
+	tb5 := NewHookedTestingTB("test5")
+	store = &fakeStore{}
+	store.Init(tb5)
+	store.On("Delete", "a").Return(nil)
+	func() {
+		defer func() {
+			recover()
+		}()
+		store.Delete("a")
+	}()
+	if !(len(tb5.Messages) == 1 && strings.HasPrefix(tb5.Messages[0], "FATAL: cannot identify the mocked method from closure ") && strings.HasSuffix(tb5.Messages[0], ".(*fakeStore).Delete.func1, use MethodCalled with the method name instead")) {
+		t.Fatalf("test5: unexpected Messages: %#v", tb5.Messages)
+	}
+}
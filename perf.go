@@ -0,0 +1,74 @@
+package goassert
+
+import (
+	"runtime"
+	"sort"
+	"time"
+)
+
+// allocsRuns is the number of the runs measuring the allocations in AllocsAtMost.
+const allocsRuns = 100
+
+// fasterThanWarmups is the number of the warmup runs in FasterThan.
+const fasterThanWarmups = 3
+
+// fasterThanRuns is the number of the measured runs in FasterThan.
+const fasterThanRuns = 31
+
+// allocsPerRun returns the average number of the allocations per run of the callback as testing.AllocsPerRun does.
+// Package testing is not imported, so that it is not linked into the non-test binaries importing package goassert.
+func allocsPerRun(runs int, callback func()) float64 {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+	// Warm up the callback.
+	callback()
+	var memstats runtime.MemStats
+	runtime.ReadMemStats(&memstats)
+	mallocs := 0 - memstats.Mallocs
+	for i := 0; i < runs; i++ {
+		callback()
+	}
+	runtime.ReadMemStats(&memstats)
+	mallocs += memstats.Mallocs
+	// The average is truncated into the integer as testing.AllocsPerRun does.
+	return float64(mallocs / uint64(runs))
+}
+
+// AllocsAtMost checks that the callback allocates at most n times per run on average.
+// The allocations are measured on one CPU after one warmup run as testing.AllocsPerRun does.
+func (assert *Assert) AllocsAtMost(n float64, callback func()) {
+	assert.tb.Helper()
+	if allocs := allocsPerRun(allocsRuns, callback); allocs > n {
+		assert.tb.Errorf("expected at most %v allocation(s) per run, but got %v allocation(s) per run (average of %d runs)", n, allocs, allocsRuns)
+	}
+}
+
+// percentile returns the p-th percentile of the sorted durations by the nearest-rank method.
+func percentile(durations []time.Duration, p int) time.Duration {
+	rank := (p*len(durations) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return durations[rank-1]
+}
+
+// FasterThan checks that the median duration of the callback is at most d.
+// The callback is called several times for warmup before the measured runs.
+// The minimum, median, 90th percentile and maximum durations are reported on failure.
+func (assert *Assert) FasterThan(d time.Duration, callback func()) {
+	assert.tb.Helper()
+	for i := 0; i < fasterThanWarmups; i++ {
+		callback()
+	}
+	durations := make([]time.Duration, fasterThanRuns)
+	for i := range durations {
+		start := time.Now()
+		callback()
+		durations[i] = time.Since(start)
+	}
+	sort.Slice(durations, func(i, j int) bool {
+		return durations[i] < durations[j]
+	})
+	if median := percentile(durations, 50); median > d {
+		assert.tb.Errorf("expected median duration at most %s, but got %s (min %s, p90 %s, max %s in %d runs)", d, median, durations[0], percentile(durations, 90), durations[len(durations)-1], len(durations))
+	}
+}
@@ -0,0 +1,95 @@
+package goassert
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAssertAllocsAtMost(t *testing.T) {
+	// test1: Test helper registration
+	tb1 := NewHookedTestingTB("test1")
+	New(tb1).AllocsAtMost(0, func() {
+	})
+	// NOTICE: We cannot identify the location of any helper exactly, because of go cover tool inserts some code into source code files.
+	if len(tb1.Helpers) != 1 {
+		t.Fatalf("test1: unexpected Helpers: %#v", tb1.Helpers)
+	}
+	// test2: only normal uses
+	var sink []byte
+	tb2 := NewHookedTestingTB("test2")
+	New(tb2).AllocsAtMost(0, func() {
+		sink = sink[:0]
+	})
+	New(tb2).AllocsAtMost(1, func() {
+		sink = make([]byte, 1024)
+	})
+	if tb2.Failed() {
+		t.Fatalf("test2: unexpected Failed() == true")
+	}
+	if len(tb2.Messages) != 0 {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	// test3: error case
+	tb3 := NewHookedTestingTB("test3")
+	New(tb3).AllocsAtMost(1, func() {
+		sink = make([]byte, 1024)
+		sink = make([]byte, 2048)
+	})
+	if !tb3.Failed() {
+		t.Fatalf("test3: unexpected Failed() == false")
+	}
+	if !reflect.DeepEqual(tb3.Messages, []string{"ERROR: expected at most 1 allocation(s) per run, but got 2 allocation(s) per run (average of 100 runs)"}) {
+		t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	durations := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	for _, testcase := range []struct {
+		p        int
+		expected time.Duration
+	}{{0, 1}, {50, 5}, {90, 9}, {91, 10}, {100, 10}} {
+		if got := percentile(durations, testcase.p); got != testcase.expected {
+			t.Fatalf("test: expected %s, but got %s returned by percentile(%d)", testcase.expected, got, testcase.p)
+		}
+	}
+}
+
+func TestAssertFasterThan(t *testing.T) {
+	// test1: Test helper registration
+	tb1 := NewHookedTestingTB("test1")
+	New(tb1).FasterThan(time.Second, func() {
+	})
+	// NOTICE: We cannot identify the location of any helper exactly, because of go cover tool inserts some code into source code files.
+	if len(tb1.Helpers) != 1 {
+		t.Fatalf("test1: unexpected Helpers: %#v", tb1.Helpers)
+	}
+	// test2: only normal uses
+	tb2 := NewHookedTestingTB("test2")
+	n := 0
+	New(tb2).FasterThan(time.Second, func() {
+		n++
+	})
+	if n != fasterThanWarmups+fasterThanRuns {
+		t.Fatalf("test2: unexpected number of calls: %d", n)
+	}
+	if tb2.Failed() {
+		t.Fatalf("test2: unexpected Failed() == true")
+	}
+	if len(tb2.Messages) != 0 {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	// test3: error case
+	tb3 := NewHookedTestingTB("test3")
+	New(tb3).FasterThan(time.Microsecond, func() {
+		time.Sleep(100 * time.Microsecond)
+	})
+	if !tb3.Failed() {
+		t.Fatalf("test3: unexpected Failed() == false")
+	}
+	if !(len(tb3.Messages) == 1 && strings.HasPrefix(tb3.Messages[0], "ERROR: expected median duration at most 1µs, but got ") && strings.HasSuffix(tb3.Messages[0], " in 31 runs)")) {
+		t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+	}
+}
@@ -0,0 +1,431 @@
+package goassert
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// prettyWidth is the maximum width of the composite value printed in one line.
+const prettyWidth = 60
+
+// prettyIndent is the indentation of the nested values printed in multiple lines.
+const prettyIndent = "  "
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// FormatArtifactsEnv is the name of the environment variable giving the directory to which the truncated values are dumped.
+const FormatArtifactsEnv = "GOASSERT_ARTIFACTS"
+
+// FormatConfig is the configuration of the value formatter in the failure messages.
+// The zero limit means the default limit, and the negative limit means no limit.
+type FormatConfig struct {
+	// MaxDepth is the max depth of the nested collections and structs, which defaults to 10.
+	MaxDepth int
+	// MaxElements is the max number of the elements printed per collection, which defaults to 100.
+	MaxElements int
+	// MaxStringLength is the max number of the bytes printed per string, which defaults to 1000.
+	MaxStringLength int
+	// MaxBytes is the max number of the bytes of the whole formatted value, which defaults to 65536.
+	MaxBytes int
+	// ArtifactsDir is the directory to which the full values are dumped if they are truncated.
+	// The empty directory means the directory given by environment variable GOASSERT_ARTIFACTS.
+	// The full values are not dumped if both are empty.
+	ArtifactsDir string
+}
+
+// DefaultFormatConfig is the configuration of the value formatter used in all the failure messages.
+var DefaultFormatConfig = &FormatConfig{}
+
+// limits returns the configuration whose limits are resolved into the positive numbers.
+func (config *FormatConfig) limits() FormatConfig {
+	limits := *config
+	for _, limit := range []struct {
+		v            *int
+		defaultLimit int
+	}{
+		{&limits.MaxDepth, 10},
+		{&limits.MaxElements, 100},
+		{&limits.MaxStringLength, 1000},
+		{&limits.MaxBytes, 65536},
+	} {
+		if *limit.v == 0 {
+			*limit.v = limit.defaultLimit
+		} else if *limit.v < 0 {
+			*limit.v = math.MaxInt
+		}
+	}
+	if limits.ArtifactsDir == "" {
+		limits.ArtifactsDir = os.Getenv(FormatArtifactsEnv)
+	}
+	return limits
+}
+
+// formatCount returns the count with the thousands separators, e.g. "4,812".
+func formatCount(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+// truncateString returns the prefix of the string within n bytes, which does not split any UTF-8 sequence.
+func truncateString(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// visit is the reference being printed.
+// The type is needed to distinguish the pointer to the struct from the pointer to its first field.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// prettyPrinter prints the values for the failure messages.
+type prettyPrinter struct {
+	limits FormatConfig
+	// visiting is the map of the references being printed to their paths, which is used for detecting the cycles.
+	visiting map[visit]string
+	// path is the path of the value being printed from the root value, e.g. ".Children[0].Parent".
+	path  string
+	depth int
+	// truncated indicates whether any part of the value is elided by the limits or not.
+	truncated bool
+}
+
+// newPrettyPrinter returns a new prettyPrinter with the limits.
+func newPrettyPrinter(limits FormatConfig) *prettyPrinter {
+	return &prettyPrinter{
+		limits:   limits,
+		visiting: map[visit]string{},
+	}
+}
+
+// enter marks the reference as being printed at the current path.
+// If the reference is already being printed, enter returns false with the back-reference to its path such as "<cycle to .Parent>".
+func (p *prettyPrinter) enter(v reflect.Value) (string, bool) {
+	key := visit{v.Pointer(), v.Type()}
+	if path, ok := p.visiting[key]; ok {
+		if path == "" {
+			path = "root"
+		}
+		return fmt.Sprintf("<cycle to %s>", path), false
+	}
+	p.visiting[key] = p.path
+	return "", true
+}
+
+// leave unmarks the reference.
+func (p *prettyPrinter) leave(v reflect.Value) {
+	delete(p.visiting, visit{v.Pointer(), v.Type()})
+}
+
+// formatLimited returns the pretty-printed value within the limits of DefaultFormatConfig.
+// If the value is truncated, the full value is dumped into the artifacts directory, and the note referencing it is also returned.
+func formatLimited(v reflect.Value) (string, string) {
+	limits := DefaultFormatConfig.limits()
+	p := newPrettyPrinter(limits)
+	s := p.print(v, true)
+	if len(s) > limits.MaxBytes {
+		truncated := truncateString(s, limits.MaxBytes)
+		s = fmt.Sprintf("%s... %s more bytes", truncated, formatCount(len(s)-len(truncated)))
+		p.truncated = true
+	}
+	if !p.truncated || limits.ArtifactsDir == "" {
+		return s, ""
+	}
+	path, err := dumpValue(limits.ArtifactsDir, v)
+	if err != nil {
+		return s, fmt.Sprintf("cannot dump full value: %s", err)
+	}
+	return s, fmt.Sprintf("full value in %s", path)
+}
+
+// dumpValue dumps the pretty-printed value without any limit into the file in the directory, and returns the path of the file.
+// The file is named after the hash of its content, so the same value formatted repeatedly, e.g. by EventuallyWith, is dumped only once.
+func dumpValue(dir string, v reflect.Value) (string, error) {
+	content := newPrettyPrinter((&FormatConfig{MaxDepth: -1, MaxElements: -1, MaxStringLength: -1, MaxBytes: -1}).limits()).print(v, true) + "\n"
+	sum := sha256.Sum256([]byte(content))
+	path := filepath.Join(dir, fmt.Sprintf("goassert-%x.txt", sum[:8]))
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	// Write into the temporary file first, so that the concurrent tests dumping the same value never see the partial file.
+	file, err := os.CreateTemp(dir, "goassert-*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+	if _, err := io.WriteString(file, content); err != nil {
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	return path, os.Rename(file.Name(), path)
+}
+
+// formatValue returns the pretty-printed value followed by its type, e.g. `[]int{1, 2} ([]int)`.
+// The composite values are printed in multiple indented lines if they are too long.
+// See FormatConfig for the limits of the formatted value.
+func formatValue(v interface{}) string {
+	return formatReflectValue(reflect.ValueOf(v))
+}
+
+// formatReflectValue returns the pretty-printed reflected value followed by its type.
+func formatReflectValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nil (<nil>)"
+	}
+	s, note := formatLimited(v)
+	if note != "" {
+		return fmt.Sprintf("%s (%s, %s)", s, v.Type(), note)
+	}
+	return fmt.Sprintf("%s (%s)", s, v.Type())
+}
+
+// prettyValue returns the pretty-printed value without its type.
+func prettyValue(v interface{}) string {
+	s, note := formatLimited(reflect.ValueOf(v))
+	if note != "" {
+		return fmt.Sprintf("%s (%s)", s, note)
+	}
+	return s
+}
+
+// compose returns the composite value with the elements, which are printed in one line if possible.
+func compose(prefix string, elems []string) string {
+	if len(elems) == 0 {
+		return prefix + "{}"
+	}
+	oneLine := prefix + "{" + strings.Join(elems, ", ") + "}"
+	if len(oneLine) <= prettyWidth && !strings.Contains(oneLine, "\n") {
+		return oneLine
+	}
+	lines := []string{prefix + "{"}
+	for _, elem := range elems {
+		lines = append(lines, prettyIndent+strings.Replace(elem, "\n", "\n"+prettyIndent, -1)+",")
+	}
+	return strings.Join(append(lines, "}"), "\n")
+}
+
+// printString prints the quoted string within the limit.
+func (p *prettyPrinter) printString(s string) string {
+	truncated := truncateString(s, p.limits.MaxStringLength)
+	if len(truncated) == len(s) {
+		return strconv.Quote(s)
+	}
+	p.truncated = true
+	return fmt.Sprintf("%s... %s more bytes", strconv.Quote(truncated), formatCount(len(s)-len(truncated)))
+}
+
+// printElements prints the elements within the limit.
+// The elided elements are represented by the marker such as "... 4,812 more elements".
+func (p *prettyPrinter) printElements(n int, print func(i int) string) []string {
+	elems := make([]string, 0, n)
+	for i := 0; i < n && i < p.limits.MaxElements; i++ {
+		elems = append(elems, print(i))
+	}
+	if n > len(elems) {
+		p.truncated = true
+		elems = append(elems, fmt.Sprintf("... %s more elements", formatCount(n-len(elems))))
+	}
+	return elems
+}
+
+// printBytes prints the byte slice as a string if it is a printable UTF-8 text.
+func (p *prettyPrinter) printBytes(prefix string, b []byte) string {
+	if utf8.Valid(b) && strings.IndexFunc(string(b), func(r rune) bool {
+		return !(unicode.IsPrint(r) || unicode.IsSpace(r))
+	}) < 0 {
+		return fmt.Sprintf("%s(%s)", prefix, p.printString(string(b)))
+	}
+	return compose(prefix, p.printElements(len(b), func(i int) string {
+		return fmt.Sprintf("0x%02x", b[i])
+	}))
+}
+
+// enterNested increments the depth of the nested values, and returns false if the depth exceeds the limit.
+func (p *prettyPrinter) enterNested() bool {
+	if p.depth >= p.limits.MaxDepth {
+		p.truncated = true
+		return false
+	}
+	p.depth++
+	return true
+}
+
+// leaveNested decrements the depth of the nested values.
+func (p *prettyPrinter) leaveNested() {
+	p.depth--
+}
+
+// printAt prints the nested value at the path segment such as ".Name" or "[0]".
+func (p *prettyPrinter) printAt(segment string, v reflect.Value, typed bool) string {
+	path := p.path
+	p.path += segment
+	defer func() {
+		p.path = path
+	}()
+	return p.print(v, typed)
+}
+
+// lessValue returns true if the map key a is ordered before b.
+// The keys of the ordered kinds are compared by their values, and the others by their printed forms.
+func (p *prettyPrinter) lessValue(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.String:
+		return a.String() < b.String()
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	}
+	return p.print(a, true) < p.print(b, true)
+}
+
+// print returns the pretty-printed value.
+// If typed is false, the type of the composite value is elided as the elements of the composite literal.
+func (p *prettyPrinter) print(v reflect.Value, typed bool) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+	typeName := ""
+	if typed {
+		typeName = v.Type().String()
+	}
+	switch v.Type() {
+	case durationType:
+		return time.Duration(v.Int()).String()
+	case timeType:
+		if v.CanInterface() {
+			return v.Interface().(time.Time).Format(time.RFC3339Nano)
+		}
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Uintptr:
+		return fmt.Sprintf("0x%x", v.Uint())
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case reflect.Complex64, reflect.Complex128:
+		return fmt.Sprintf("%v", v.Complex())
+	case reflect.String:
+		return p.printString(v.String())
+	case reflect.Interface:
+		if v.IsNil() {
+			return "nil"
+		}
+		return p.print(v.Elem(), true)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return fmt.Sprintf("(%s)(nil)", v.Type())
+		}
+		if cycle, ok := p.enter(v); !ok {
+			return cycle
+		}
+		defer p.leave(v)
+		return "&" + p.print(v.Elem(), typed)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice {
+			if v.IsNil() {
+				return fmt.Sprintf("%s(nil)", v.Type())
+			}
+			if v.Len() > 0 {
+				if cycle, ok := p.enter(v); !ok {
+					return cycle
+				}
+				defer p.leave(v)
+			}
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 && v.Kind() == reflect.Slice {
+			return p.printBytes(typeName, v.Bytes())
+		}
+		if !p.enterNested() {
+			return typeName + "{...}"
+		}
+		defer p.leaveNested()
+		return compose(typeName, p.printElements(v.Len(), func(i int) string {
+			return p.printAt(fmt.Sprintf("[%d]", i), v.Index(i), false)
+		}))
+	case reflect.Map:
+		if v.IsNil() {
+			return fmt.Sprintf("%s(nil)", v.Type())
+		}
+		if cycle, ok := p.enter(v); !ok {
+			return cycle
+		}
+		defer p.leave(v)
+		if !p.enterNested() {
+			return typeName + "{...}"
+		}
+		defer p.leaveNested()
+		keys := v.MapKeys()
+		sort.SliceStable(keys, func(i, j int) bool {
+			return p.lessValue(keys[i], keys[j])
+		})
+		return compose(typeName, p.printElements(len(keys), func(i int) string {
+			key := p.print(keys[i], false)
+			return key + ": " + p.printAt("["+key+"]", v.MapIndex(keys[i]), false)
+		}))
+	case reflect.Struct:
+		if !p.enterNested() {
+			return typeName + "{...}"
+		}
+		defer p.leaveNested()
+		elems := make([]string, v.NumField())
+		for i := range elems {
+			name := v.Type().Field(i).Name
+			elems[i] = name + ": " + p.printAt("."+name, v.Field(i), true)
+		}
+		return compose(typeName, elems)
+	case reflect.Chan:
+		if v.IsNil() {
+			return fmt.Sprintf("(%s)(nil)", v.Type())
+		}
+		return fmt.Sprintf("(%s)", describeChan(v))
+	case reflect.Func:
+		if v.IsNil() {
+			return fmt.Sprintf("(%s)(nil)", v.Type())
+		}
+		return fmt.Sprintf("(%s)(...)", v.Type())
+	case reflect.UnsafePointer:
+		return fmt.Sprintf("unsafe.Pointer(0x%x)", v.Pointer())
+	}
+	return fmt.Sprintf("%v", v)
+}
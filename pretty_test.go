@@ -0,0 +1,137 @@
+package goassert
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatValue(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+	type node struct {
+		Name string
+		Next *node
+	}
+	type config struct {
+		Name     string
+		Timeout  time.Duration
+		Created  time.Time
+		Points   []point
+		Labels   map[string]int
+		Payload  []byte
+		Callback func()
+	}
+	cycle := &node{Name: "a"}
+	cycle.Next = &node{Name: "b", Next: cycle}
+	cyclicSlice := []interface{}{1, nil}
+	cyclicSlice[1] = cyclicSlice
+	x := 42
+	for i, testcase := range []struct {
+		v        interface{}
+		expected string
+	}{
+		{nil, "nil (<nil>)"},
+		{"hello", "\"hello\" (string)"},
+		{uint8(255), "255 (uint8)"},
+		{1.5, "1.5 (float64)"},
+		{[]int{1, 2}, "[]int{1, 2} ([]int)"},
+		{[]int(nil), "[]int(nil) ([]int)"},
+		{map[int]string{10: "b", 2: "a"}, "map[int]string{2: \"a\", 10: \"b\"} (map[int]string)"},
+		{&x, "&42 (*int)"},
+		{(*int)(nil), "(*int)(nil) (*int)"},
+		{[]point{{1, 2}}, "[]goassert.point{{X: 1, Y: 2}} ([]goassert.point)"},
+		{[]interface{}{1, point{}}, "[]interface {}{1, goassert.point{X: 0, Y: 0}} ([]interface {})"},
+		{[]byte("hello\n"), "[]uint8(\"hello\\n\") ([]uint8)"},
+		{[]byte{0xff, 0}, "[]uint8{0xff, 0x00} ([]uint8)"},
+		{1500 * time.Millisecond, "1.5s (time.Duration)"},
+		{time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC), "2017-01-02T03:04:05Z (time.Time)"},
+		{cycle, "&goassert.node{\n  Name: \"a\",\n  Next: &goassert.node{Name: \"b\", Next: <cycle to root>},\n} (*goassert.node)"},
+		{cyclicSlice, "[]interface {}{1, <cycle to root>} ([]interface {})"},
+		{config{Name: "c", Timeout: time.Second, Points: []point{{1, 2}}, Labels: map[string]int{"b": 2, "a": 1}, Payload: []byte("p")}, "goassert.config{\n" +
+			"  Name: \"c\",\n" +
+			"  Timeout: 1s,\n" +
+			"  Created: 0001-01-01T00:00:00Z,\n" +
+			"  Points: []goassert.point{{X: 1, Y: 2}},\n" +
+			"  Labels: map[string]int{\"a\": 1, \"b\": 2},\n" +
+			"  Payload: []uint8(\"p\"),\n" +
+			"  Callback: (func())(nil),\n" +
+			"} (goassert.config)"},
+		{[][]string{{"a very long string element"}, {"another very long string element"}}, "[][]string{\n  {\"a very long string element\"},\n  {\"another very long string element\"},\n} ([][]string)"},
+	} {
+		if got := formatValue(testcase.v); got != testcase.expected {
+			t.Fatalf("test #%d: expected %q, but got %q", i, testcase.expected, got)
+		}
+	}
+	if got := formatReflectValue(reflect.ValueOf(point{}).Field(0)); got != "0 (int)" {
+		t.Fatalf("test: unexpected formatReflectValue: %q", got)
+	}
+	if got := formatReflectValue(reflect.Value{}); got != "nil (<nil>)" {
+		t.Fatalf("test: unexpected formatReflectValue: %q", got)
+	}
+}
+
+func TestFormatValueLimits(t *testing.T) {
+	defer func(config *FormatConfig) {
+		DefaultFormatConfig = config
+	}(DefaultFormatConfig)
+	type n struct {
+		N *n
+	}
+	deep := &n{&n{&n{}}}
+	large := make([]int, 5000)
+	// test1: truncated values
+	DefaultFormatConfig = &FormatConfig{MaxDepth: 2, MaxElements: 3, MaxStringLength: 5, MaxBytes: 60}
+	for i, testcase := range []struct {
+		v        interface{}
+		expected string
+	}{
+		{large, "[]int{0, 0, 0, ... 4,997 more elements} ([]int)"},
+		{map[int]int{1: 1, 2: 2, 3: 3, 4: 4}, "map[int]int{1: 1, 2: 2, 3: 3, ... 1 more elements} (map[int]int)"},
+		{"hello, world", "\"hello\"... 7 more bytes (string)"},
+		{"こんにちは", "\"こ\"... 12 more bytes (string)"},
+		{[]byte("hello, world"), "[]uint8(\"hello\"... 7 more bytes) ([]uint8)"},
+		{deep, "&goassert.n{N: &goassert.n{N: &goassert.n{...}}} (*goassert.n)"},
+		{map[string]string{"aaaaa": "aaaaa", "bbbbb": "bbbbb", "ccccc": "ccccc"}, "map[string]string{\n  \"aaaaa\": \"aaaaa\",\n  \"bbbbb\": \"bbbbb\",\n ... 20 more bytes (map[string]string)"},
+	} {
+		if got := formatValue(testcase.v); got != testcase.expected {
+			t.Fatalf("test1 #%d: expected %q, but got %q", i, testcase.expected, got)
+		}
+	}
+	// test2: unlimited values
+	DefaultFormatConfig = &FormatConfig{MaxDepth: -1, MaxElements: -1, MaxStringLength: -1, MaxBytes: -1}
+	if got := formatValue(large); len(got) != len("[]int{\n")+5000*len("  0,\n")+len("} ([]int)") {
+		t.Fatalf("test2: unexpected length of the formatted value: %d", len(got))
+	}
+	// test3: full values dumped into the artifacts directory
+	dir := t.TempDir()
+	DefaultFormatConfig = &FormatConfig{MaxElements: 2, ArtifactsDir: dir}
+	got := formatValue([]int{1, 2, 3})
+	if !(strings.HasPrefix(got, "[]int{1, 2, ... 1 more elements} ([]int, full value in "+dir+"/goassert-") && strings.HasSuffix(got, ".txt)")) {
+		t.Fatalf("test3: unexpected formatted value: %q", got)
+	}
+	path := strings.TrimSuffix(strings.TrimPrefix(got, "[]int{1, 2, ... 1 more elements} ([]int, full value in "), ")")
+	if data, err := os.ReadFile(path); err != nil || string(data) != "[]int{1, 2, 3}\n" {
+		t.Fatalf("test3: unexpected dumped value: %q, %v", data, err)
+	}
+	if got := formatValue([]int{1, 2}); got != "[]int{1, 2} ([]int)" {
+		t.Fatalf("test3: unexpected formatted value: %q", got)
+	}
+	if again := formatValue([]int{1, 2, 3}); again != got {
+		t.Fatalf("test3: expected %q, but got %q", got, again)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, "*")); len(matches) != 1 {
+		t.Fatalf("test3: expected only one dumped file, but got %q", matches)
+	}
+	// test4: environment variable
+	os.Setenv(FormatArtifactsEnv, dir)
+	defer os.Unsetenv(FormatArtifactsEnv)
+	DefaultFormatConfig = &FormatConfig{MaxStringLength: 1}
+	if got := prettyValue("ab"); !strings.HasPrefix(got, "\"a\"... 1 more bytes (full value in "+dir+"/goassert-") {
+		t.Fatalf("test4: unexpected formatted value: %q", got)
+	}
+}
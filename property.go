@@ -0,0 +1,488 @@
+package goassert
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PropertySeedEnv is the name of the environment variable overriding the seed of property-based testing.
+const PropertySeedEnv = "GOASSERT_SEED"
+
+// PropertyConfig is the configuration of property-based testing.
+type PropertyConfig struct {
+	// Runs is the number of the random cases, which defaults to 100.
+	Runs int
+	// Seed is the seed of the random generator.
+	// The zero seed means the seed given by environment variable GOASSERT_SEED or the current time.
+	Seed int64
+	// MaxShrinks is the max number of the property evaluations in shrinking, which defaults to 1000.
+	MaxShrinks int
+}
+
+// Property checks that the property holds for the random arguments.
+// The property must be func(*Assert, ...), and the arguments following *Assert are generated randomly.
+// The failing arguments are shrunk to a minimal counterexample, which is reported with the seed reproducing it.
+// See PropertyConfig for the default configuration.
+func Property(tb TestingTB, property interface{}) {
+	tb.Helper()
+	(&PropertyConfig{}).Check(tb, property)
+}
+
+// limits returns the number of the random cases and the max number of the property evaluations in shrinking.
+func (config *PropertyConfig) limits() (int, int) {
+	runs, maxShrinks := config.Runs, config.MaxShrinks
+	if runs <= 0 {
+		runs = 100
+	}
+	if maxShrinks <= 0 {
+		maxShrinks = 1000
+	}
+	return runs, maxShrinks
+}
+
+// seed returns the seed of the random generator.
+func (config *PropertyConfig) seed(tb TestingTB) int64 {
+	tb.Helper()
+	if config.Seed != 0 {
+		return config.Seed
+	}
+	if env := os.Getenv(PropertySeedEnv); env != "" {
+		seed, err := strconv.ParseInt(env, 10, 64)
+		if err != nil {
+			tb.Fatalf("malformed %s: %s", PropertySeedEnv, err)
+		}
+		return seed
+	}
+	return time.Now().UnixNano()
+}
+
+// Check checks that the property holds for the random arguments.
+// See Property for details.
+func (config *PropertyConfig) Check(tb TestingTB, property interface{}) {
+	tb.Helper()
+	f := reflect.ValueOf(property)
+	if f.Kind() != reflect.Func || f.Type().NumIn() < 1 || f.Type().In(0) != reflect.TypeOf((*Assert)(nil)) || f.Type().NumOut() != 0 {
+		tb.Fatalf("property must be func(*Assert, ...), but got %T", property)
+		return
+	}
+	if f.Type().IsVariadic() {
+		tb.Fatalf("property must not be variadic, but got %T", property)
+		return
+	}
+	argTypes := make([]reflect.Type, f.Type().NumIn()-1)
+	for i := range argTypes {
+		argTypes[i] = f.Type().In(i + 1)
+	}
+	runs, maxShrinks := config.limits()
+	seed := config.seed(tb)
+	rnd := rand.New(rand.NewSource(seed))
+	check := func(args []reflect.Value) *Collect {
+		return checkProperty(tb, func(a *Assert) {
+			f.Call(append([]reflect.Value{reflect.ValueOf(a)}, args...))
+		})
+	}
+	for run := 0; run < runs; run++ {
+		size := run * 100 / runs
+		args := make([]reflect.Value, len(argTypes))
+		for i, argType := range argTypes {
+			arg, err := generateValue(rnd, argType, size)
+			if err != nil {
+				tb.Fatalf("cannot generate #%d argument: %s", i, err)
+				return
+			}
+			args[i] = arg
+		}
+		if c := check(args); c.failed {
+			args, c, shrinks := shrinkArgs(args, c, maxShrinks, check)
+			reportPropertyFailure(tb, run+1, seed, shrinks, formatArgs(args), c)
+			return
+		}
+	}
+}
+
+// checkProperty calls the property with a new Assert on Collect, and returns the Collect.
+// The panic in the property is reported as a failure.
+func checkProperty(tb TestingTB, property func(a *Assert)) *Collect {
+	c := &Collect{name: tb.Name(), color: colorEnabled(tb)}
+	c.run(func(c *Collect) {
+		defer func() {
+			if v := recover(); v != nil {
+				if _, ok := v.(collectAbort); ok {
+					panic(v)
+				}
+				c.Errorf("panic: %v", v)
+			}
+		}()
+		property(New(c))
+	})
+	return c
+}
+
+// reportPropertyFailure reports the failed property with the shrunk counterexample and the seed reproducing it.
+func reportPropertyFailure(tb TestingTB, runs int, seed int64, shrinks int, counterexample string, c *Collect) {
+	tb.Helper()
+	tb.Errorf("property failed after %d case(s) (seed %d, rerun with %s=%d)\nminimal counterexample (shrunk %d time(s)):\n%s\nfailed with:\n%s",
+		runs, seed, PropertySeedEnv, seed, shrinks, counterexample, strings.Join(c.messages, "\n"))
+}
+
+// formatArgs returns the description of the arguments.
+func formatArgs(args []reflect.Value) string {
+	lines := make([]string, len(args))
+	for i, arg := range args {
+		lines[i] = fmt.Sprintf("  #%d: %s", i, formatReflectValue(arg))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// shrinkArgs shrinks the failing arguments greedily as long as the property keeps failing.
+// shrinkArgs returns the shrunk arguments, the collected failure of them and the number of the successful shrinks.
+func shrinkArgs(args []reflect.Value, c *Collect, maxShrinks int, check func([]reflect.Value) *Collect) ([]reflect.Value, *Collect, int) {
+	shrinks, evaluations := 0, 0
+	for improved := true; improved && evaluations < maxShrinks; {
+		improved = false
+		for i := 0; i < len(args) && !improved && evaluations < maxShrinks; i++ {
+			for _, candidate := range shrinkValue(args[i]) {
+				if evaluations >= maxShrinks {
+					break
+				}
+				evaluations++
+				trial := append([]reflect.Value{}, args...)
+				trial[i] = candidate
+				if trialCollect := check(trial); trialCollect.failed {
+					args, c = trial, trialCollect
+					shrinks++
+					improved = true
+					break
+				}
+			}
+		}
+	}
+	return args, c, shrinks
+}
+
+// maxGenerateDepth is the maximum nesting depth of the pointers, slices and maps in the generated values.
+// The pointers are nil and the collections are empty beyond the depth, so the self-referential types such as the trees are generated finitely.
+const maxGenerateDepth = 8
+
+// generateValue generates a random value of the type.
+// The size bounds the magnitude of the numbers and the lengths of the collections.
+// The unsupported types are reported regardless of the random choices, e.g. the empty slice of them.
+func generateValue(rnd *rand.Rand, t reflect.Type, size int) (reflect.Value, error) {
+	if err := checkGeneratable(t, map[reflect.Type]bool{}); err != nil {
+		return reflect.New(t).Elem(), err
+	}
+	return generateValueAt(rnd, t, size, 0)
+}
+
+// checkGeneratable returns the error if the values of the type cannot be generated.
+// The visited types are not checked again for the self-referential types.
+func checkGeneratable(t reflect.Type, visited map[reflect.Type]bool) error {
+	if visited[t] {
+		return nil
+	}
+	visited[t] = true
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.String:
+		return nil
+	case reflect.Slice, reflect.Array, reflect.Ptr:
+		return checkGeneratable(t.Elem(), visited)
+	case reflect.Map:
+		if err := checkGeneratable(t.Key(), visited); err != nil {
+			return err
+		}
+		return checkGeneratable(t.Elem(), visited)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).PkgPath != "" {
+				continue
+			}
+			if err := checkGeneratable(t.Field(i).Type, visited); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot generate value of type %s", t)
+}
+
+// generateValueAt generates a random value of the type nested in the pointers, slices and maps of the depth.
+func generateValueAt(rnd *rand.Rand, t reflect.Type, size, depth int) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	if depth >= maxGenerateDepth {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map:
+			return v, nil
+		}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		v.SetBool(rnd.Intn(2) == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if rnd.Intn(16) == 0 {
+			v.SetInt(int64(rnd.Uint64()))
+		} else {
+			v.SetInt(rnd.Int63n(int64(2*size+1)) - int64(size))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if rnd.Intn(16) == 0 {
+			v.SetUint(rnd.Uint64())
+		} else {
+			v.SetUint(uint64(rnd.Int63n(int64(size + 1))))
+		}
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat((2*rnd.Float64() - 1) * float64(size))
+	case reflect.Complex64, reflect.Complex128:
+		v.SetComplex(complex((2*rnd.Float64()-1)*float64(size), (2*rnd.Float64()-1)*float64(size)))
+	case reflect.String:
+		runes := make([]rune, rnd.Intn(size+1))
+		for i := range runes {
+			if rnd.Intn(10) == 0 {
+				runes[i] = rune(0xa0 + rnd.Intn(0x3000))
+			} else {
+				runes[i] = rune(0x20 + rnd.Intn(0x5f))
+			}
+		}
+		v.SetString(string(runes))
+	case reflect.Slice:
+		n := rnd.Intn(size + 1)
+		v.Set(reflect.MakeSlice(t, n, n))
+		fallthrough
+	case reflect.Array:
+		elemDepth := depth
+		if t.Kind() == reflect.Slice {
+			elemDepth++
+		}
+		for i := 0; i < v.Len(); i++ {
+			elem, err := generateValueAt(rnd, t.Elem(), size, elemDepth)
+			if err != nil {
+				return v, err
+			}
+			v.Index(i).Set(elem)
+		}
+	case reflect.Map:
+		v.Set(reflect.MakeMap(t))
+		for n := rnd.Intn(size + 1); n > 0; n-- {
+			key, err := generateValueAt(rnd, t.Key(), size, depth+1)
+			if err != nil {
+				return v, err
+			}
+			elem, err := generateValueAt(rnd, t.Elem(), size, depth+1)
+			if err != nil {
+				return v, err
+			}
+			v.SetMapIndex(key, elem)
+		}
+	case reflect.Ptr:
+		if rnd.Intn(10) != 0 {
+			elem, err := generateValueAt(rnd, t.Elem(), size, depth+1)
+			if err != nil {
+				return v, err
+			}
+			v.Set(reflect.New(t.Elem()))
+			v.Elem().Set(elem)
+		}
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			// The unexported fields cannot be set, so they are left zero.
+			if t.Field(i).PkgPath != "" {
+				continue
+			}
+			field, err := generateValueAt(rnd, t.Field(i).Type, size, depth)
+			if err != nil {
+				return v, err
+			}
+			v.Field(i).Set(field)
+		}
+	case reflect.Interface:
+		if t.NumMethod() > 0 {
+			return v, fmt.Errorf("cannot generate value of type %s", t)
+		}
+		types := []reflect.Type{reflect.TypeOf(false), reflect.TypeOf(0), reflect.TypeOf(0.0), reflect.TypeOf("")}
+		elem, err := generateValueAt(rnd, types[rnd.Intn(len(types))], size, depth)
+		if err != nil {
+			return v, err
+		}
+		v.Set(elem)
+	default:
+		return v, fmt.Errorf("cannot generate value of type %s", t)
+	}
+	return v, nil
+}
+
+// shrinkValue returns the candidates simpler than the value.
+func shrinkValue(v reflect.Value) []reflect.Value {
+	t := v.Type()
+	candidates := []reflect.Value{}
+	add := func(x interface{}) {
+		candidates = append(candidates, reflect.ValueOf(x).Convert(t))
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			add(false)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x := v.Int()
+		if x == 0 {
+			break
+		}
+		add(int64(0))
+		if x < 0 && !v.OverflowInt(-x) {
+			add(-x)
+		}
+		if x/2 != 0 {
+			add(x / 2)
+		}
+		step := int64(1)
+		if x < 0 {
+			step = -1
+		}
+		if x-step != 0 && x-step != x/2 {
+			add(x - step)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if x := v.Uint(); x > 0 {
+			add(uint64(0))
+			if x/2 > 0 {
+				add(x / 2)
+			}
+			if x-1 > 0 && x-1 != x/2 {
+				add(x - 1)
+			}
+		}
+	case reflect.Float32, reflect.Float64:
+		if x := v.Float(); x != 0 {
+			add(0.0)
+			if math.IsNaN(x) || math.IsInf(x, 0) {
+				break
+			}
+			if y := math.Trunc(x); y != x && y != 0 {
+				add(y)
+			}
+			if x < 0 {
+				add(-x)
+			}
+		}
+	case reflect.Complex64, reflect.Complex128:
+		if x := v.Complex(); x != 0 {
+			add(complex128(0))
+			if imag(x) != 0 && real(x) != 0 {
+				add(complex(real(x), 0))
+			}
+		}
+	case reflect.String:
+		runes := []rune(v.String())
+		if len(runes) == 0 {
+			break
+		}
+		add("")
+		if len(runes) > 1 {
+			add(string(runes[:len(runes)/2]))
+			add(string(runes[len(runes)/2:]))
+		}
+		for i := range runes {
+			add(string(runes[:i]) + string(runes[i+1:]))
+		}
+		for i, r := range runes {
+			if r != 'a' {
+				add(string(runes[:i]) + "a" + string(runes[i+1:]))
+			}
+		}
+	case reflect.Slice:
+		if v.IsNil() || v.Len() == 0 {
+			break
+		}
+		slice := func(start, end int) reflect.Value {
+			return reflect.AppendSlice(reflect.MakeSlice(t, 0, end-start), v.Slice(start, end))
+		}
+		candidates = append(candidates, reflect.MakeSlice(t, 0, 0))
+		if n := v.Len(); n > 1 {
+			candidates = append(candidates, slice(0, n/2), slice(n/2, n))
+		}
+		for i := 0; i < v.Len(); i++ {
+			candidates = append(candidates, reflect.AppendSlice(slice(0, i), v.Slice(i+1, v.Len())))
+		}
+		fallthrough
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			for _, elem := range shrinkValue(v.Index(i)) {
+				candidate := reflect.New(t).Elem()
+				if t.Kind() == reflect.Slice {
+					candidate.Set(reflect.AppendSlice(reflect.MakeSlice(t, 0, v.Len()), v))
+				} else {
+					candidate.Set(v)
+				}
+				candidate.Index(i).Set(elem)
+				candidates = append(candidates, candidate)
+			}
+		}
+	case reflect.Map:
+		if v.IsNil() || v.Len() == 0 {
+			break
+		}
+		candidates = append(candidates, reflect.MakeMap(t))
+		clone := func() reflect.Value {
+			m := reflect.MakeMapWithSize(t, v.Len())
+			for _, key := range v.MapKeys() {
+				m.SetMapIndex(key, v.MapIndex(key))
+			}
+			return m
+		}
+		for _, key := range v.MapKeys() {
+			m := clone()
+			m.SetMapIndex(key, reflect.Value{})
+			candidates = append(candidates, m)
+		}
+		for _, key := range v.MapKeys() {
+			for _, elem := range shrinkValue(v.MapIndex(key)) {
+				m := clone()
+				m.SetMapIndex(key, elem)
+				candidates = append(candidates, m)
+			}
+		}
+	case reflect.Ptr:
+		if v.IsNil() {
+			break
+		}
+		candidates = append(candidates, reflect.Zero(t))
+		for _, elem := range shrinkValue(v.Elem()) {
+			p := reflect.New(t.Elem())
+			p.Elem().Set(elem)
+			candidates = append(candidates, p)
+		}
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).PkgPath != "" {
+				continue
+			}
+			for _, field := range shrinkValue(v.Field(i)) {
+				candidate := reflect.New(t).Elem()
+				candidate.Set(v)
+				candidate.Field(i).Set(field)
+				candidates = append(candidates, candidate)
+			}
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			break
+		}
+		for _, elem := range shrinkValue(v.Elem()) {
+			candidate := reflect.New(t).Elem()
+			candidate.Set(elem)
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates
+}
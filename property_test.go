@@ -0,0 +1,183 @@
+package goassert
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerateValue(t *testing.T) {
+	type S struct {
+		A int
+		B *string
+		c []int
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for _, v := range []interface{}{false, int8(0), uint(0), 0.0, complex64(0), "", []int{}, [2]string{}, map[string]bool{}, (*int)(nil), S{}, []interface{}{}} {
+		for size := 0; size <= 100; size += 10 {
+			generated, err := generateValue(rnd, reflect.TypeOf(v), size)
+			if err != nil {
+				t.Fatalf("test: unexpected error: %s", err)
+			}
+			if generated.Type() != reflect.TypeOf(v) {
+				t.Fatalf("test: unexpected type: %s", generated.Type())
+			}
+		}
+	}
+	// The self-referential types are generated within the depth limit.
+	type tree struct {
+		L, R     *tree
+		Children []tree
+		V        int
+	}
+	var depth func(v *tree) int
+	depth = func(v *tree) int {
+		if v == nil {
+			return 0
+		}
+		d := 0
+		for _, sub := range []*tree{v.L, v.R} {
+			if sd := depth(sub); sd > d {
+				d = sd
+			}
+		}
+		for i := range v.Children {
+			if sd := depth(&v.Children[i]); sd > d {
+				d = sd
+			}
+		}
+		return d + 1
+	}
+	for i := 0; i < 10; i++ {
+		generated, err := generateValue(rnd, reflect.TypeOf(&tree{}), 3)
+		if err != nil {
+			t.Fatalf("test: unexpected error: %s", err)
+		}
+		if d := depth(generated.Interface().(*tree)); d > maxGenerateDepth {
+			t.Fatalf("test: unexpected depth %d", d)
+		}
+	}
+	for _, v := range []interface{}{make(chan int), func() {}, []error{}, (*chan int)(nil)} {
+		if _, err := generateValue(rnd, reflect.TypeOf(v), 10); err == nil {
+			t.Fatalf("test: unexpected nil error for %T", v)
+		}
+	}
+}
+
+func TestShrinkValue(t *testing.T) {
+	for _, testcase := range []struct {
+		v        interface{}
+		expected []interface{}
+	}{
+		{true, []interface{}{false}},
+		{false, []interface{}{}},
+		{10, []interface{}{0, 5, 9}},
+		{int8(-128), []interface{}{int8(0), int8(-64), int8(-127)}},
+		{-2, []interface{}{0, 2, -1}},
+		{uint(1), []interface{}{uint(0)}},
+		{-1.5, []interface{}{0.0, -1.0, 1.5}},
+		{"ab", []interface{}{"", "a", "b", "b", "a", "aa"}},
+		{[]int{1, 0}, []interface{}{[]int{}, []int{1}, []int{0}, []int{0}, []int{1}, []int{0, 0}}},
+		{map[string]int{"a": 0}, []interface{}{map[string]int{}, map[string]int{}}},
+		{struct{ A, b bool }{true, true}, []interface{}{struct{ A, b bool }{false, true}}},
+	} {
+		got := []interface{}{}
+		for _, candidate := range shrinkValue(reflect.ValueOf(testcase.v)) {
+			got = append(got, candidate.Interface())
+		}
+		if !reflect.DeepEqual(testcase.expected, got) {
+			t.Fatalf("test: expected %#v, but got %#v returned by shrinkValue(%#v)", testcase.expected, got, testcase.v)
+		}
+	}
+}
+
+func TestProperty(t *testing.T) {
+	// test1: Test helper registration
+	tb1 := NewHookedTestingTB("test1")
+	Property(tb1, func(a *Assert, x int) {
+	})
+	// NOTICE: We cannot identify the location of any helper exactly, because of go cover tool inserts some code into source code files.
+	if len(tb1.Helpers) != 3 {
+		t.Fatalf("test1: unexpected Helpers: %#v", tb1.Helpers)
+	}
+	// test2: only normal uses
+	tb2 := NewHookedTestingTB("test2")
+	n := 0
+	Property(tb2, func(a *Assert, x, y int, s []string) {
+		n++
+		a.New(x + y).Equal(y + x)
+		a.New(len(s)).Equal(len(append([]string{}, s...)))
+	})
+	if n != 100 {
+		t.Fatalf("test2: unexpected number of runs: %d", n)
+	}
+	if tb2.Failed() {
+		t.Fatalf("test2: unexpected Failed() == true")
+	}
+	if len(tb2.Messages) != 0 {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	// test3: error cases with shrinking
+	tb3 := NewHookedTestingTB("test3")
+	config := &PropertyConfig{Seed: 1}
+	config.Check(tb3, func(a *Assert, x int) {
+		a.New(true).Equal(x < 10)
+	})
+	config.Check(tb3, func(a *Assert, s []string) {
+		if len(s) >= 3 {
+			panic("too long")
+		}
+	})
+	if !tb3.Failed() {
+		t.Fatalf("test3: unexpected Failed() == false")
+	}
+	if !(len(tb3.Messages) == 2 &&
+		strings.HasPrefix(tb3.Messages[0], "ERROR: property failed after ") &&
+		strings.Contains(tb3.Messages[0], " (seed 1, rerun with GOASSERT_SEED=1)\nminimal counterexample (shrunk ") &&
+		strings.HasSuffix(tb3.Messages[0], "  #0: 10 (int)\nfailed with:\nERROR: at #0 value, expected true (bool), but got false (bool)") &&
+		strings.HasSuffix(tb3.Messages[1], "  #0: []string{\"\", \"\", \"\"} ([]string)\nfailed with:\nERROR: panic: too long")) {
+		t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+	}
+	// test4: fatal exit cases
+	for i, testcase := range []struct {
+		property interface{}
+		message  string
+	}{
+		{1, "FATAL: property must be func(*Assert, ...), but got int"},
+		{func(x int) {}, "FATAL: property must be func(*Assert, ...), but got func(int)"},
+		{func(a *Assert) bool { return true }, "FATAL: property must be func(*Assert, ...), but got func(*goassert.Assert) bool"},
+		{func(a *Assert, xs ...int) {}, "FATAL: property must not be variadic, but got func(*goassert.Assert, ...int)"},
+		{func(a *Assert, x int, ch chan int) {}, "FATAL: cannot generate #1 argument: cannot generate value of type chan int"},
+	} {
+		tb4 := NewHookedTestingTB("test4")
+		func() {
+			defer func() {
+				recover()
+			}()
+			Property(tb4, testcase.property)
+		}()
+		if !tb4.Failed() {
+			t.Fatalf("test4 #%d: unexpected Failed() == false", i)
+		}
+		if !reflect.DeepEqual(tb4.Messages, []string{testcase.message}) {
+			t.Fatalf("test4 #%d: unexpected Messages: %#v", i, tb4.Messages)
+		}
+	}
+	// test5: seed given by the environment variable
+	t.Setenv(PropertySeedEnv, "42")
+	if seed := (&PropertyConfig{}).seed(NewHookedTestingTB("test5")); seed != 42 {
+		t.Fatalf("test5: unexpected seed: %d", seed)
+	}
+	t.Setenv(PropertySeedEnv, "x")
+	tb5 := NewHookedTestingTB("test5")
+	func() {
+		defer func() {
+			recover()
+		}()
+		Property(tb5, func(a *Assert) {})
+	}()
+	if !reflect.DeepEqual(tb5.Messages, []string{"FATAL: malformed GOASSERT_SEED: strconv.ParseInt: parsing \"x\": invalid syntax"}) {
+		t.Fatalf("test5: unexpected Messages: %#v", tb5.Messages)
+	}
+}
@@ -0,0 +1,219 @@
+package goassert
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// status returns the result of the test, which is "fail", "skip" or "pass".
+// The failure takes precedence over the skip as package testing does.
+func (tb *HookedTestingTB) status() string {
+	switch {
+	case tb.Failed():
+		return "fail"
+	case tb.Skipped():
+		return "skip"
+	}
+	return "pass"
+}
+
+// failedItself returns true if the test has failed by itself, i.e. it has failed without subtests or has logged the error.
+// Run marks the test failed if any subtest fails, so the failures of the subtests are not regarded as its own failure.
+func (tb *HookedTestingTB) failedItself() bool {
+	if len(tb.Subtests) == 0 {
+		return tb.Failed()
+	}
+	for _, message := range tb.Messages {
+		if strings.HasPrefix(message, "ERROR: ") || strings.HasPrefix(message, "FATAL: ") {
+			return true
+		}
+	}
+	return false
+}
+
+// walk calls the function with the test and its subtests in pre-order.
+func (tb *HookedTestingTB) walk(f func(tb *HookedTestingTB)) {
+	f(tb)
+	for _, sub := range tb.Subtests {
+		sub.walk(f)
+	}
+}
+
+// junitFailure is the element failure or skipped of JUnit XML.
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Contents string `xml:",chardata"`
+}
+
+// junitTestCase is the element testcase of JUnit XML.
+type junitTestCase struct {
+	XMLName   xml.Name      `xml:"testcase"`
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitFailure `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitTestSuite is the element testsuite of JUnit XML.
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr,omitempty"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+// junitTestSuites is the root element testsuites of JUnit XML.
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTime returns the attribute time of the duration in seconds, or the empty string if the duration is not measured.
+func junitTime(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// WriteJUnitXML writes the results of the tests in JUnit XML.
+// Each test is written as the test suite, whose test cases are the test itself and all of its subtests without subtests.
+// The test with subtests is written only if it has its own messages, and it is failed only if it has failed by itself,
+// so that the failures of the subtests are not counted twice.
+// The messages are written as the output of the test cases, and the unmeasured durations such as the ones of the top-level tests are omitted.
+func WriteJUnitXML(w io.Writer, tbs ...*HookedTestingTB) error {
+	suites := junitTestSuites{TestSuites: []junitTestSuite{}}
+	for _, tb := range tbs {
+		suite := junitTestSuite{
+			Name: tb.Name(),
+			Time: junitTime(tb.Duration),
+		}
+		tb.walk(func(test *HookedTestingTB) {
+			if len(test.Subtests) > 0 && len(test.Messages) == 0 {
+				return
+			}
+			output := strings.Join(test.Messages, "\n")
+			testCase := junitTestCase{
+				Name:      test.Name(),
+				Classname: tb.Name(),
+				Time:      junitTime(test.Duration),
+				SystemOut: output,
+			}
+			switch {
+			case test.failedItself():
+				testCase.Failure = &junitFailure{Message: "Failed", Contents: output}
+				suite.Failures++
+			case test.Skipped():
+				testCase.Skipped = &junitFailure{Message: "Skipped", Contents: output}
+				suite.Skipped++
+			}
+			suite.Tests++
+			suite.TestCases = append(suite.TestCases, testCase)
+		})
+		suites.TestSuites = append(suites.TestSuites, suite)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// writeTAPTests writes the test points of the tests indented by the prefix.
+// The subtests are written as the indented subtests preceded by the comment "# Subtest: name".
+func writeTAPTests(w *bufio.Writer, prefix string, tbs []*HookedTestingTB) {
+	fmt.Fprintf(w, "%s1..%d\n", prefix, len(tbs))
+	for i, tb := range tbs {
+		if len(tb.Subtests) > 0 {
+			fmt.Fprintf(w, "%s    # Subtest: %s\n", prefix, tb.Name())
+			writeTAPTests(w, prefix+"    ", tb.Subtests)
+		}
+		switch tb.status() {
+		case "fail":
+			fmt.Fprintf(w, "%snot ok %d - %s\n", prefix, i+1, tb.Name())
+		case "skip":
+			fmt.Fprintf(w, "%sok %d - %s # SKIP\n", prefix, i+1, tb.Name())
+		default:
+			fmt.Fprintf(w, "%sok %d - %s\n", prefix, i+1, tb.Name())
+		}
+		fmt.Fprintf(w, "%s  ---\n", prefix)
+		fmt.Fprintf(w, "%s  duration_ms: %.3f\n", prefix, float64(tb.Duration.Microseconds())/1000)
+		if len(tb.Messages) > 0 {
+			fmt.Fprintf(w, "%s  messages:\n", prefix)
+			for _, message := range tb.Messages {
+				fmt.Fprintf(w, "%s    - |-\n", prefix)
+				for _, line := range strings.Split(message, "\n") {
+					fmt.Fprintf(w, "%s      %s\n", prefix, line)
+				}
+			}
+		}
+		fmt.Fprintf(w, "%s  ...\n", prefix)
+	}
+}
+
+// WriteTAP writes the results of the tests in TAP version 13.
+// The duration and messages of each test are written in its YAML block.
+func WriteTAP(w io.Writer, tbs ...*HookedTestingTB) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "TAP version 13\n")
+	writeTAPTests(bw, "", tbs)
+	return bw.Flush()
+}
+
+// testEvent is the event of go test -json, see cmd/test2json for details.
+type testEvent struct {
+	Action  string
+	Test    string   `json:",omitempty"`
+	Elapsed *float64 `json:",omitempty"`
+	Output  string   `json:",omitempty"`
+}
+
+// testEvents returns the events of the test and its subtests as go test -json does.
+func (tb *HookedTestingTB) testEvents(depth int) []testEvent {
+	indent := strings.Repeat("    ", depth)
+	events := []testEvent{
+		{Action: "run", Test: tb.Name()},
+		{Action: "output", Test: tb.Name(), Output: fmt.Sprintf("=== RUN   %s\n", tb.Name())},
+	}
+	for _, message := range tb.Messages {
+		events = append(events, testEvent{Action: "output", Test: tb.Name(), Output: fmt.Sprintf("%s    %s\n", indent, strings.ReplaceAll(message, "\n", "\n"+indent+"        "))})
+	}
+	for _, sub := range tb.Subtests {
+		events = append(events, sub.testEvents(depth+1)...)
+	}
+	status, elapsed := tb.status(), tb.Duration.Seconds()
+	events = append(events,
+		testEvent{Action: "output", Test: tb.Name(), Output: fmt.Sprintf("%s--- %s: %s (%.2fs)\n", indent, strings.ToUpper(status), tb.Name(), elapsed)},
+		testEvent{Action: status, Test: tb.Name(), Elapsed: &elapsed},
+	)
+	return events
+}
+
+// WriteTestJSON writes the results of the tests in the event stream of go test -json.
+// The events have no field Time and Package, because HookedTestingTB does not know them.
+func WriteTestJSON(w io.Writer, tbs ...*HookedTestingTB) error {
+	encoder := json.NewEncoder(w)
+	for _, tb := range tbs {
+		for _, event := range tb.testEvents(0) {
+			if err := encoder.Encode(event); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
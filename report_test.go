@@ -0,0 +1,164 @@
+package goassert
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// newReportedTestingTB returns the test with the passed, failed and skipped subtests.
+func newReportedTestingTB() *HookedTestingTB {
+	tb := NewHookedTestingTB("TestSuite")
+	tb.Run("pass", func(tb *HookedTestingTB) {
+		tb.Log("hello")
+	})
+	tb.Run("fail", func(tb *HookedTestingTB) {
+		tb.Errorf("expected <a>,\nbut got b")
+	})
+	tb.Run("skip", func(tb *HookedTestingTB) {
+		tb.Skip("later")
+	})
+	tb.Duration = 1500 * time.Millisecond
+	for i, sub := range tb.Subtests {
+		sub.Duration = time.Duration(i+1) * 250 * time.Millisecond
+	}
+	return tb
+}
+
+func TestWriteJUnitXML(t *testing.T) {
+	var buf bytes.Buffer
+	parent := NewHookedTestingTB("TestParent")
+	parent.Errorf("setup failed")
+	parent.Run("sub", func(tb *HookedTestingTB) {})
+	parent.Subtests[0].Duration = 0
+	logging := NewHookedTestingTB("TestLogging")
+	logging.Log("setting up")
+	logging.Run("sub", func(tb *HookedTestingTB) {
+		tb.Error("failed")
+	})
+	logging.Subtests[0].Duration = 0
+	if err := WriteJUnitXML(&buf, newReportedTestingTB(), NewHookedTestingTB("TestEmpty"), parent, logging); err != nil {
+		t.Fatalf("test: unexpected error: %s", err)
+	}
+	if expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="TestSuite" tests="3" failures="1" skipped="1" time="1.500">
+    <testcase name="TestSuite/pass" classname="TestSuite" time="0.250">
+      <system-out>hello</system-out>
+    </testcase>
+    <testcase name="TestSuite/fail" classname="TestSuite" time="0.500">
+      <failure message="Failed">ERROR: expected &lt;a&gt;,&#xA;but got b</failure>
+      <system-out>ERROR: expected &lt;a&gt;,&#xA;but got b</system-out>
+    </testcase>
+    <testcase name="TestSuite/skip" classname="TestSuite" time="0.750">
+      <skipped message="Skipped">SKIP: later</skipped>
+      <system-out>SKIP: later</system-out>
+    </testcase>
+  </testsuite>
+  <testsuite name="TestEmpty" tests="1" failures="0" skipped="0">
+    <testcase name="TestEmpty" classname="TestEmpty"></testcase>
+  </testsuite>
+  <testsuite name="TestParent" tests="2" failures="1" skipped="0">
+    <testcase name="TestParent" classname="TestParent">
+      <failure message="Failed">ERROR: setup failed</failure>
+      <system-out>ERROR: setup failed</system-out>
+    </testcase>
+    <testcase name="TestParent/sub" classname="TestParent"></testcase>
+  </testsuite>
+  <testsuite name="TestLogging" tests="2" failures="1" skipped="0">
+    <testcase name="TestLogging" classname="TestLogging">
+      <system-out>setting up</system-out>
+    </testcase>
+    <testcase name="TestLogging/sub" classname="TestLogging">
+      <failure message="Failed">ERROR: failed</failure>
+      <system-out>ERROR: failed</system-out>
+    </testcase>
+  </testsuite>
+</testsuites>
+`; buf.String() != expected {
+		t.Fatalf("test: unexpected JUnit XML:\n%s", buf.String())
+	}
+}
+
+func TestWriteTAP(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteTAP(&buf, newReportedTestingTB(), NewHookedTestingTB("TestEmpty")); err != nil {
+		t.Fatalf("test: unexpected error: %s", err)
+	}
+	if expected := `TAP version 13
+1..2
+    # Subtest: TestSuite
+    1..3
+    ok 1 - TestSuite/pass
+      ---
+      duration_ms: 250.000
+      messages:
+        - |-
+          hello
+      ...
+    not ok 2 - TestSuite/fail
+      ---
+      duration_ms: 500.000
+      messages:
+        - |-
+          ERROR: expected <a>,
+          but got b
+      ...
+    ok 3 - TestSuite/skip # SKIP
+      ---
+      duration_ms: 750.000
+      messages:
+        - |-
+          SKIP: later
+      ...
+not ok 1 - TestSuite
+  ---
+  duration_ms: 1500.000
+  ...
+ok 2 - TestEmpty
+  ---
+  duration_ms: 0.000
+  ...
+`; buf.String() != expected {
+		t.Fatalf("test: unexpected TAP:\n%s", buf.String())
+	}
+}
+
+func TestWriteTestJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteTestJSON(&buf, newReportedTestingTB()); err != nil {
+		t.Fatalf("test: unexpected error: %s", err)
+	}
+	if expected := `{"Action":"run","Test":"TestSuite"}
+{"Action":"output","Test":"TestSuite","Output":"=== RUN   TestSuite\n"}
+{"Action":"run","Test":"TestSuite/pass"}
+{"Action":"output","Test":"TestSuite/pass","Output":"=== RUN   TestSuite/pass\n"}
+{"Action":"output","Test":"TestSuite/pass","Output":"        hello\n"}
+{"Action":"output","Test":"TestSuite/pass","Output":"    --- PASS: TestSuite/pass (0.25s)\n"}
+{"Action":"pass","Test":"TestSuite/pass","Elapsed":0.25}
+{"Action":"run","Test":"TestSuite/fail"}
+{"Action":"output","Test":"TestSuite/fail","Output":"=== RUN   TestSuite/fail\n"}
+{"Action":"output","Test":"TestSuite/fail","Output":"        ERROR: expected \u003ca\u003e,\n            but got b\n"}
+{"Action":"output","Test":"TestSuite/fail","Output":"    --- FAIL: TestSuite/fail (0.50s)\n"}
+{"Action":"fail","Test":"TestSuite/fail","Elapsed":0.5}
+{"Action":"run","Test":"TestSuite/skip"}
+{"Action":"output","Test":"TestSuite/skip","Output":"=== RUN   TestSuite/skip\n"}
+{"Action":"output","Test":"TestSuite/skip","Output":"        SKIP: later\n"}
+{"Action":"output","Test":"TestSuite/skip","Output":"    --- SKIP: TestSuite/skip (0.75s)\n"}
+{"Action":"skip","Test":"TestSuite/skip","Elapsed":0.75}
+{"Action":"output","Test":"TestSuite","Output":"--- FAIL: TestSuite (1.50s)\n"}
+{"Action":"fail","Test":"TestSuite","Elapsed":1.5}
+`; buf.String() != expected {
+		t.Fatalf("test: unexpected events:\n%s", buf.String())
+	}
+}
+
+func TestHookedTestingTBRunDuration(t *testing.T) {
+	tb := NewHookedTestingTB("test")
+	tb.Run("sleep", func(tb *HookedTestingTB) {
+		time.Sleep(10 * time.Millisecond)
+	})
+	if tb.Subtests[0].Duration < 10*time.Millisecond {
+		t.Fatalf("test: unexpected Duration: %s", tb.Subtests[0].Duration)
+	}
+}
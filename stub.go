@@ -0,0 +1,265 @@
+package goassert
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// StubRoute is the expectation of the requests to StubServer with the canned response.
+type StubRoute struct {
+	method      string
+	path        string
+	query       url.Values
+	header      http.Header
+	body        *string
+	bodyJSON    interface{}
+	bodyPattern *regexp.Regexp
+	times       int
+	calls       int
+	status      int
+	respHeader  http.Header
+	respBody    []byte
+}
+
+// String returns the short description of the route.
+func (route *StubRoute) String() string {
+	if len(route.query) > 0 {
+		return fmt.Sprintf("%s %s?%s", route.method, route.path, route.query.Encode())
+	}
+	return fmt.Sprintf("%s %s", route.method, route.path)
+}
+
+// WithQuery expects the query parameter, and returns the route.
+// The other query parameters are ignored.
+func (route *StubRoute) WithQuery(key, value string) *StubRoute {
+	route.query.Add(key, value)
+	return route
+}
+
+// WithHeader expects the header field, and returns the route.
+// The other header fields are ignored.
+func (route *StubRoute) WithHeader(key, value string) *StubRoute {
+	route.header.Add(key, value)
+	return route
+}
+
+// WithBody expects the body, and returns the route.
+func (route *StubRoute) WithBody(body string) *StubRoute {
+	route.body = &body
+	return route
+}
+
+// WithJSON expects the body semantically equal to the JSON, and returns the route.
+// The JSON is interpreted as HTTPResponse.ExpectJSON does.
+// WithJSON panics if the JSON is malformed.
+func (route *StubRoute) WithJSON(v interface{}) *StubRoute {
+	decoded, _, err := normalizeJSON(v)
+	if err != nil {
+		panic(fmt.Sprintf("goassert: malformed JSON of %s: %s", route, err))
+	}
+	route.bodyJSON = decoded
+	return route
+}
+
+// WithBodyMatches expects the body matching the pattern, and returns the route.
+// WithBodyMatches panics if the pattern is malformed.
+func (route *StubRoute) WithBodyMatches(pattern string) *StubRoute {
+	route.bodyPattern = regexp.MustCompile(pattern)
+	return route
+}
+
+// Times expects the route to be called n times, and returns the route.
+// The route is expected to be called once by default, and any number of times if n is negative.
+func (route *StubRoute) Times(n int) *StubRoute {
+	route.times = n
+	return route
+}
+
+// Respond sets the status code and the body of the response, and returns the route.
+// The route responds with status 200 and the empty body by default.
+func (route *StubRoute) Respond(status int, body string) *StubRoute {
+	route.status, route.respBody = status, []byte(body)
+	return route
+}
+
+// RespondJSON sets the status code and the JSON encoding of v as the response with header Content-Type "application/json", and returns the route.
+// RespondJSON panics if v cannot be encoded.
+func (route *StubRoute) RespondJSON(status int, v interface{}) *StubRoute {
+	_, encoded, err := normalizeJSON(v)
+	if err != nil {
+		panic(fmt.Sprintf("goassert: cannot encode response of %s: %s", route, err))
+	}
+	route.respHeader.Set("Content-Type", "application/json")
+	return route.Respond(status, encoded)
+}
+
+// RespondHeader adds the header field to the response, and returns the route.
+func (route *StubRoute) RespondHeader(key, value string) *StubRoute {
+	route.respHeader.Add(key, value)
+	return route
+}
+
+// mismatches returns the descriptions of the differences between the expectation and the request.
+// If color is true, the differences of the bodies are colored.
+func (route *StubRoute) mismatches(r *http.Request, body []byte, color bool) []string {
+	diffs := []string{}
+	if r.Method != route.method {
+		diffs = append(diffs, fmt.Sprintf("method: expected %q, but got %q", route.method, r.Method))
+	}
+	if r.URL.Path != route.path {
+		diffs = append(diffs, fmt.Sprintf("path: expected %q, but got %q", route.path, r.URL.Path))
+	}
+	keys := []string{}
+	for key := range route.query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	query := r.URL.Query()
+	for _, key := range keys {
+		if !reflect.DeepEqual(route.query[key], query[key]) {
+			diffs = append(diffs, fmt.Sprintf("query %s: expected %q, but got %q", key, route.query[key], query[key]))
+		}
+	}
+	keys = []string{}
+	for key := range route.header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !reflect.DeepEqual(route.header[key], r.Header[key]) {
+			diffs = append(diffs, fmt.Sprintf("header %s: expected %q, but got %q", key, route.header[key], r.Header[key]))
+		}
+	}
+	if route.body != nil && *route.body != string(body) {
+		diffs = append(diffs, fmt.Sprintf("body:\n%s", diffLines(*route.body, string(body), color)))
+	}
+	if route.bodyJSON != nil {
+		if decoded, actualJSON, err := normalizeJSON(body); err != nil {
+			diffs = append(diffs, fmt.Sprintf("body: malformed JSON: %s", err))
+		} else if !reflect.DeepEqual(route.bodyJSON, decoded) {
+			_, expectedJSON, _ := normalizeJSON(route.bodyJSON)
+			diffs = append(diffs, fmt.Sprintf("JSON body:\n%s", diffLines(expectedJSON, actualJSON, color)))
+		}
+	}
+	if route.bodyPattern != nil && !route.bodyPattern.Match(body) {
+		diffs = append(diffs, fmt.Sprintf("body: expected pattern %q, but got %q", route.bodyPattern, body))
+	}
+	return diffs
+}
+
+// StubServer is the local HTTP server responding the canned responses of the expected routes.
+// The unmatched requests fail the test, and the unmet expectations are reported at the cleanup of the testing context.
+type StubServer struct {
+	tb TestingTB
+	// Server is the underlying server.
+	Server *httptest.Server
+	mu     sync.Mutex
+	routes []*StubRoute
+}
+
+// NewStubServer starts a new StubServer, which is closed by the cleanup of the testing context.
+func NewStubServer(tb TestingTB) *StubServer {
+	tb.Helper()
+	server := &StubServer{tb: tb}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.serveHTTP))
+	tb.Cleanup(func() {
+		server.Server.Close()
+		server.verify()
+	})
+	return server
+}
+
+// URL returns the base URL of the server.
+func (server *StubServer) URL() string {
+	return server.Server.URL
+}
+
+// Expect registers the route expecting the method and the path, and returns the route.
+func (server *StubServer) Expect(method, path string) *StubRoute {
+	route := &StubRoute{
+		method:     method,
+		path:       path,
+		query:      url.Values{},
+		header:     http.Header{},
+		times:      1,
+		status:     http.StatusOK,
+		respHeader: http.Header{},
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.routes = append(server.routes, route)
+	return route
+}
+
+// serveHTTP responds the canned response of the first matched route which is not exhausted.
+// The unmatched request is reported with the differences against the nearest route, and responded with status 501.
+func (server *StubServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		server.tb.Errorf("cannot read body of request %s %s: %s", r.Method, r.URL.RequestURI(), err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	var nearest, exhausted *StubRoute
+	var nearestDiffs []string
+	nearestPrimary := 0
+	for _, route := range server.routes {
+		diffs := route.mismatches(r, body, colorEnabled(server.tb))
+		if len(diffs) == 0 {
+			if route.times >= 0 && route.calls >= route.times {
+				if exhausted == nil {
+					exhausted = route
+				}
+				continue
+			}
+			route.calls++
+			for key, values := range route.respHeader {
+				w.Header()[key] = append([]string{}, values...)
+			}
+			w.WriteHeader(route.status)
+			w.Write(route.respBody)
+			return
+		}
+		// The nearest route is the one with the fewest differences in the method and the path, and then in the others.
+		primary := 0
+		if r.Method != route.method {
+			primary++
+		}
+		if r.URL.Path != route.path {
+			primary++
+		}
+		if nearest == nil || primary < nearestPrimary || (primary == nearestPrimary && len(diffs) < len(nearestDiffs)) {
+			nearest, nearestDiffs, nearestPrimary = route, diffs, primary
+		}
+	}
+	switch {
+	case exhausted != nil:
+		server.tb.Errorf("unexpected request %s %s: %s is expected to be called %d time(s), but called %d time(s)", r.Method, r.URL.RequestURI(), exhausted, exhausted.times, exhausted.calls+1)
+	case nearest != nil:
+		server.tb.Errorf("unmatched request %s %s, differences from the nearest expectation %s:\n%s", r.Method, r.URL.RequestURI(), nearest, strings.Join(nearestDiffs, "\n"))
+	default:
+		server.tb.Errorf("unmatched request %s %s: no expectation", r.Method, r.URL.RequestURI())
+	}
+	http.Error(w, "unmatched request", http.StatusNotImplemented)
+}
+
+// verify reports the unmet expectations.
+func (server *StubServer) verify() {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	for _, route := range server.routes {
+		if route.times >= 0 && route.calls < route.times {
+			server.tb.Errorf("unmet expectation %s: expected %d call(s), but got %d call(s)", route, route.times, route.calls)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package goassert
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// stubRequest sends the request to the server, and returns the status code and the body.
+func stubRequest(t *testing.T, method, url, body string, header http.Header) (int, string) {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("cannot create request: %s", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("cannot send request: %s", err)
+	}
+	defer res.Body.Close()
+	data, _ := io.ReadAll(res.Body)
+	return res.StatusCode, string(data)
+}
+
+func TestStubServer(t *testing.T) {
+	// test1: Test helper registration
+	tb1 := NewHookedTestingTB("test1")
+	NewStubServer(tb1)
+	// NOTICE: We cannot identify the location of any helper exactly, because of go cover tool inserts some code into source code files.
+	if len(tb1.Helpers) != 1 {
+		t.Fatalf("test1: unexpected Helpers: %#v", tb1.Helpers)
+	}
+	tb1.DoCleanups()
+	// test2: only normal uses
+	tb2 := NewHookedTestingTB("test2")
+	server := NewStubServer(tb2)
+	server.Expect("GET", "/users").WithQuery("page", "2").Respond(http.StatusOK, "[]")
+	server.Expect("POST", "/users").WithHeader("Authorization", "secret").WithJSON(`{"name": "gopher"}`).RespondJSON(http.StatusCreated, map[string]int{"id": 1}).RespondHeader("Location", "/users/1")
+	server.Expect("GET", "/health").WithBodyMatches(`^$`).Times(-1)
+	if status, body := stubRequest(t, "GET", server.URL()+"/users?page=2&sort=name", "", nil); !(status == http.StatusOK && body == "[]") {
+		t.Fatalf("test2: unexpected response: %d %q", status, body)
+	}
+	if status, body := stubRequest(t, "POST", server.URL()+"/users", `{ "name":"gopher" }`, http.Header{"Authorization": {"secret"}}); !(status == http.StatusCreated && body == "{\n  \"id\": 1\n}") {
+		t.Fatalf("test2: unexpected response: %d %q", status, body)
+	}
+	for i := 0; i < 3; i++ {
+		stubRequest(t, "GET", server.URL()+"/health", "", nil)
+	}
+	tb2.DoCleanups()
+	if tb2.Failed() {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+	// test3: error cases
+	tb3 := NewHookedTestingTB("test3")
+	server = NewStubServer(tb3)
+	server.Expect("POST", "/users").WithBody("name=gopher\n").WithQuery("dry", "1")
+	server.Expect("GET", "/users")
+	server.Expect("DELETE", "/users/1").Times(2)
+	stubRequest(t, "POST", server.URL()+"/users?dry=0", "name=gohper\n", nil)
+	stubRequest(t, "GET", server.URL()+"/users", "", nil)
+	if status, _ := stubRequest(t, "GET", server.URL()+"/users", "", nil); status != http.StatusNotImplemented {
+		t.Fatalf("test3: unexpected status: %d", status)
+	}
+	stubRequest(t, "PUT", server.URL()+"/users/1", "", nil)
+	tb3.DoCleanups()
+	if !reflect.DeepEqual(tb3.Messages, []string{
+		"ERROR: unmatched request POST /users?dry=0, differences from the nearest expectation POST /users?dry=1:\nquery dry: expected [\"1\"], but got [\"0\"]\nbody:\n- name=gopher\n+ name=gohper\n  ",
+		"ERROR: unexpected request GET /users: GET /users is expected to be called 1 time(s), but called 2 time(s)",
+		"ERROR: unmatched request PUT /users/1, differences from the nearest expectation DELETE /users/1:\nmethod: expected \"DELETE\", but got \"PUT\"",
+		"ERROR: unmet expectation POST /users?dry=1: expected 1 call(s), but got 0 call(s)",
+		"ERROR: unmet expectation DELETE /users/1: expected 2 call(s), but got 0 call(s)",
+	}) {
+		t.Fatalf("test3: unexpected Messages: %#v", tb3.Messages)
+	}
+}
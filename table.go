@@ -0,0 +1,126 @@
+package goassert
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// TableConfig is the configuration of table-driven tests.
+type TableConfig struct {
+	// Parallel indicates whether the cases are run in parallel or not.
+	// The cases are run in parallel only if the subtests support method Parallel as *testing.T does.
+	Parallel bool
+}
+
+// tableCaseField returns the field of the case named name or its lower-case name.
+func tableCaseField(tc reflect.Value, name string, kind reflect.Kind) (reflect.Value, bool) {
+	for tc.Kind() == reflect.Ptr || tc.Kind() == reflect.Interface {
+		if tc.IsNil() {
+			return reflect.Value{}, false
+		}
+		tc = tc.Elem()
+	}
+	if tc.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	for _, fieldName := range []string{name, string(name[0]-'A'+'a') + name[1:]} {
+		if field := tc.FieldByName(fieldName); field.IsValid() && field.Kind() == kind {
+			return field, true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// testingTBType is the type of interface TestingTB.
+var testingTBType = reflect.TypeOf((*TestingTB)(nil)).Elem()
+
+// runSubtest runs the function as the subtest of the testing context, and returns true if the subtest has not failed.
+// The testing context must have method Run(name string, f func(T)) bool where T implements TestingTB, e.g. *testing.T, *testing.B or *HookedTestingTB.
+// The method is called via reflection, so that package testing is not linked into the non-test binaries.
+func runSubtest(tb TestingTB, name string, f func(sub TestingTB)) bool {
+	tb.Helper()
+	if run := reflect.ValueOf(tb).MethodByName("Run"); run.IsValid() {
+		t := run.Type()
+		if t.NumIn() == 2 && t.In(0) == reflect.TypeOf("") && t.In(1).Kind() == reflect.Func && t.NumOut() == 1 && t.Out(0) == reflect.TypeOf(false) &&
+			t.In(1).NumIn() == 1 && t.In(1).NumOut() == 0 && t.In(1).In(0).Implements(testingTBType) {
+			body := reflect.MakeFunc(t.In(1), func(args []reflect.Value) []reflect.Value {
+				f(args[0].Interface().(TestingTB))
+				return nil
+			})
+			return run.Call([]reflect.Value{reflect.ValueOf(name), body})[0].Bool()
+		}
+	}
+	tb.Fatalf("subtests are not supported by %T", tb)
+	return false
+}
+
+// Table runs the test for each case as the subtest.
+// The testing context must be *testing.T, *testing.B or *HookedTestingTB.
+// The subtests are named by string field Name (or name) of the cases, or by "#index" if it is empty or absent.
+// If any case has true bool field Only (or only), only such cases are run.
+// The cases having true bool field Skip (or skip) are skipped.
+// The failed case is logged in the subtest.
+func Table[T any](tb TestingTB, cases []T, test func(a *Assert, tc T)) {
+	tb.Helper()
+	TableWithConfig(tb, &TableConfig{}, cases, test)
+}
+
+// TableWithConfig runs the test for each case as the subtest with the configuration.
+// See Table for details.
+func TableWithConfig[T any](tb TestingTB, config *TableConfig, cases []T, test func(a *Assert, tc T)) {
+	tb.Helper()
+	runTable(tb, config, cases, nil, test)
+}
+
+// runTable is the implementation of TableWithConfig.
+// If locations is not nil, the location of each case is logged with the failed case.
+func runTable[T any](tb TestingTB, config *TableConfig, cases []T, locations []string, test func(a *Assert, tc T)) {
+	tb.Helper()
+	focused := false
+	for _, tc := range cases {
+		if only, ok := tableCaseField(reflect.ValueOf(&tc).Elem(), "Only", reflect.Bool); ok && only.Bool() {
+			focused = true
+		}
+	}
+	for i, tc := range cases {
+		i, tc := i, tc
+		v := reflect.ValueOf(&tc).Elem()
+		name := fmt.Sprintf("#%d", i)
+		if field, ok := tableCaseField(v, "Name", reflect.String); ok && field.String() != "" {
+			name = field.String()
+		}
+		skip := ""
+		if field, ok := tableCaseField(v, "Skip", reflect.Bool); ok && field.Bool() {
+			skip = "skipped by field Skip"
+		} else if field, ok := tableCaseField(v, "Only", reflect.Bool); focused && !(ok && field.Bool()) {
+			skip = "skipped by other cases with field Only"
+		}
+		runSubtest(tb, name, func(sub TestingTB) {
+			if skip != "" {
+				if skipper, ok := sub.(interface {
+					Skip(args ...interface{})
+				}); ok {
+					skipper.Skip(skip)
+				}
+				return
+			}
+			if config.Parallel {
+				if parallel, ok := sub.(interface {
+					Parallel()
+				}); ok {
+					parallel.Parallel()
+				}
+			}
+			defer func() {
+				if sub.Failed() {
+					if locations != nil {
+						sub.Logf("%s: failed case #%d: %s", locations[i], i, prettyValue(tc))
+					} else {
+						sub.Logf("failed case #%d: %s", i, prettyValue(tc))
+					}
+				}
+			}()
+			test(New(sub), tc)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package goassert
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTable(t *testing.T) {
+	type testcase struct {
+		name     string
+		x, y     int
+		expected int
+	}
+	// test1: normal uses with *testing.T
+	for _, config := range []*TableConfig{{}, {Parallel: true}} {
+		Table(t, []testcase{
+			{"one", 1, 0, 1},
+			{"two", 1, 1, 2},
+		}, func(a *Assert, tc testcase) {
+			a.New(tc.expected).Equal(tc.x + tc.y)
+		})
+		TableWithConfig(t, config, []int{1, 2, 3}, func(a *Assert, tc int) {
+			a.New(true).Equal(tc > 0)
+		})
+	}
+	// test2: error cases
+	tb2 := NewHookedTestingTB("test2")
+	Table(tb2, []testcase{
+		{"one", 1, 0, 1},
+		{"two", 1, 1, 3},
+		{"", 1, 2, 4},
+	}, func(a *Assert, tc testcase) {
+		a.New(tc.expected).Equal(tc.x + tc.y)
+	})
+	if !tb2.Failed() {
+		t.Fatalf("test2: unexpected Failed() == false")
+	}
+	if len(tb2.Subtests) != 3 {
+		t.Fatalf("test2: unexpected Subtests: %#v", tb2.Subtests)
+	}
+	for i, expected := range []struct {
+		name     string
+		messages []string
+	}{
+		{"test2/one", []string{}},
+		{"test2/two", []string{
+			"ERROR: at #0 value, expected 3 (int), but got 2 (int)",
+			"failed case #1: goassert.testcase{name: \"two\", x: 1, y: 1, expected: 3}",
+		}},
+		{"test2/#2", []string{
+			"ERROR: at #0 value, expected 4 (int), but got 3 (int)",
+			"failed case #2: goassert.testcase{name: \"\", x: 1, y: 2, expected: 4}",
+		}},
+	} {
+		if sub := tb2.Subtests[i]; !(sub.Name() == expected.name && reflect.DeepEqual(sub.Messages, expected.messages)) {
+			t.Fatalf("test2: unexpected Subtests[%d]: %#v", i, sub)
+		}
+	}
+	// test3: focused and skipped cases
+	type focusedcase struct {
+		Name       string
+		Only, Skip bool
+	}
+	tb3 := NewHookedTestingTB("test3")
+	run := []string{}
+	Table(tb3, []*focusedcase{
+		{Name: "a"},
+		{Name: "b", Only: true},
+		{Name: "c", Only: true, Skip: true},
+	}, func(a *Assert, tc *focusedcase) {
+		run = append(run, tc.Name)
+	})
+	if tb3.Failed() {
+		t.Fatalf("test3: unexpected Failed() == true")
+	}
+	if !reflect.DeepEqual(run, []string{"b"}) {
+		t.Fatalf("test3: unexpected run cases: %#v", run)
+	}
+	for i, expected := range []struct {
+		messages []string
+		skipped  bool
+	}{
+		{[]string{"SKIP: skipped by other cases with field Only"}, true},
+		{[]string{}, false},
+		{[]string{"SKIP: skipped by field Skip"}, true},
+	} {
+		if sub := tb3.Subtests[i]; !(reflect.DeepEqual(sub.Messages, expected.messages) && sub.Skipped() == expected.skipped) {
+			t.Fatalf("test3: unexpected Subtests[%d]: %#v", i, sub)
+		}
+	}
+	// test4: fatal exit case
+	c := &Collect{name: "test4"}
+	c.run(func(c *Collect) {
+		Table(c, []int{1}, func(a *Assert, tc int) {
+		})
+	})
+	if !c.Failed() {
+		t.Fatalf("test4: unexpected Failed() == false")
+	}
+	if !reflect.DeepEqual(c.messages, []string{"FATAL: subtests are not supported by *goassert.Collect"}) {
+		t.Fatalf("test4: unexpected messages: %#v", c.messages)
+	}
+}
@@ -0,0 +1,171 @@
+package goassert
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// jsonLineOf returns the 1-based line number of the first token at or after the offset.
+func jsonLineOf(src []byte, offset int64) int {
+	for offset < int64(len(src)) && bytes.IndexByte([]byte(" \t\r\n,"), src[offset]) >= 0 {
+		offset++
+	}
+	return bytes.Count(src[:offset], []byte("\n")) + 1
+}
+
+// parseJSONCases parses the JSON document which must be an array of the cases.
+// parseJSONCases returns the cases in JSON and their line numbers.
+func parseJSONCases(src []byte) ([]json.RawMessage, []int, error) {
+	decoder := json.NewDecoder(bytes.NewReader(src))
+	if token, err := decoder.Token(); err != nil {
+		return nil, nil, err
+	} else if token != json.Delim('[') {
+		return nil, nil, fmt.Errorf("line %d: expected array of cases", jsonLineOf(src, 0))
+	}
+	cases, lines := []json.RawMessage{}, []int{}
+	for decoder.More() {
+		line := jsonLineOf(src, decoder.InputOffset())
+		var tc json.RawMessage
+		if err := decoder.Decode(&tc); err != nil {
+			return nil, nil, fmt.Errorf("line %d: %s", line, err)
+		}
+		cases, lines = append(cases, tc), append(lines, line)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, nil, err
+	}
+	return cases, lines, nil
+}
+
+// csvFieldTypes returns the types of the fields named by the JSON names of the type, which are lower-cased.
+func csvFieldTypes(t reflect.Type) map[string]reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	types := map[string]reflect.Type{}
+	if t.Kind() != reflect.Struct {
+		return types
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := field.Name
+		if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		types[strings.ToLower(name)] = field.Type
+	}
+	return types
+}
+
+// parseCSVCases parses the CSV document whose header row names the fields of the cases.
+// The cells of the non-string fields are decoded as JSON values if possible.
+// parseCSVCases returns the cases in JSON and their line numbers.
+func parseCSVCases(src []byte, t reflect.Type) ([]json.RawMessage, []int, error) {
+	reader := csv.NewReader(bytes.NewReader(src))
+	header, err := reader.Read()
+	if err == io.EOF {
+		return []json.RawMessage{}, []int{}, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+	fieldTypes := csvFieldTypes(t)
+	cases, lines := []json.RawMessage{}, []int{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		tc := map[string]interface{}{}
+		for i, cell := range record {
+			fieldType, ok := fieldTypes[strings.ToLower(header[i])]
+			if ok && fieldType.Kind() != reflect.String && cell == "" {
+				continue
+			}
+			if ok && fieldType.Kind() != reflect.String && json.Valid([]byte(cell)) {
+				tc[header[i]] = json.RawMessage(cell)
+			} else {
+				tc[header[i]] = cell
+			}
+		}
+		raw, err := json.Marshal(tc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %s", line, err)
+		}
+		cases, lines = append(cases, raw), append(lines, line)
+	}
+	return cases, lines, nil
+}
+
+// LoadCases loads the cases from the testdata file, and returns them with their locations "path:line".
+// The format of the file is detected by its extension:
+//
+//	.json         an array of the cases
+//	.yaml, .yml   a block sequence of the cases in the subset of YAML
+//	.csv          the rows of the cases with the header row naming the fields
+//
+// The cases are decoded by encoding/json, so the fields are named by their JSON names.
+func LoadCases[T any](tb TestingTB, path string) ([]T, []string) {
+	tb.Helper()
+	src, err := os.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("cannot load cases: %s", err)
+		return nil, nil
+	}
+	var raws []json.RawMessage
+	var lines []int
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		raws, lines, err = parseJSONCases(src)
+	case ".yaml", ".yml":
+		raws, lines, err = parseYAMLCases(string(src))
+	case ".csv":
+		raws, lines, err = parseCSVCases(src, reflect.TypeOf((*T)(nil)).Elem())
+	default:
+		tb.Fatalf("cannot load cases from %s: unknown extension %q", path, ext)
+		return nil, nil
+	}
+	if err != nil {
+		tb.Fatalf("cannot load cases from %s: %s", path, err)
+		return nil, nil
+	}
+	cases, locations := make([]T, len(raws)), make([]string, len(raws))
+	for i, raw := range raws {
+		locations[i] = fmt.Sprintf("%s:%d", path, lines[i])
+		if err := json.Unmarshal(raw, &cases[i]); err != nil {
+			tb.Fatalf("%s: cannot decode case #%d: %s", locations[i], i, err)
+			return nil, nil
+		}
+	}
+	return cases, locations
+}
+
+// TableFile loads the cases from the testdata file, and runs the test for each case as Table does.
+// The location of the failed case is logged as "path:line".
+// See LoadCases for the format of the file.
+func TableFile[T any](tb TestingTB, path string, test func(a *Assert, tc T)) {
+	tb.Helper()
+	TableFileWithConfig(tb, &TableConfig{}, path, test)
+}
+
+// TableFileWithConfig loads the cases from the testdata file, and runs the test for each case with the configuration.
+// See TableFile for details.
+func TableFileWithConfig[T any](tb TestingTB, config *TableConfig, path string, test func(a *Assert, tc T)) {
+	tb.Helper()
+	cases, locations := LoadCases[T](tb, path)
+	runTable(tb, config, cases, locations, test)
+}
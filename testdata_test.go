@@ -0,0 +1,113 @@
+package goassert
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// addCase is the test vector of addition in testdata/add.*.
+type addCase struct {
+	Name string   `json:"name"`
+	X    int      `json:"x"`
+	Y    int      `json:"y"`
+	Sum  int      `json:"sum"`
+	Tags []string `json:"tags"`
+}
+
+func TestLoadCases(t *testing.T) {
+	// test1: only normal uses
+	for _, testcase := range []struct {
+		path  string
+		lines []int
+	}{
+		{"testdata/add.json", []int{2, 3, 9}},
+		{"testdata/add.yaml", []int{3, 8, 12}},
+		{"testdata/add.csv", []int{2, 3, 4}},
+	} {
+		tb1 := NewHookedTestingTB("test1")
+		cases, locations := LoadCases[addCase](tb1, testcase.path)
+		if tb1.Failed() {
+			t.Fatalf("test1: unexpected Messages for %s: %#v", testcase.path, tb1.Messages)
+		}
+		if !reflect.DeepEqual(cases, []addCase{
+			{"zero", 0, 0, 0, nil},
+			{"wrong", 1, 2, 4, nil},
+			{"negative", -1, 1, 0, []string{"sign"}},
+		}) {
+			t.Fatalf("test1: unexpected cases for %s: %#v", testcase.path, cases)
+		}
+		for i, line := range testcase.lines {
+			if expected := testcase.path + ":" + strconv.Itoa(line); locations[i] != expected {
+				t.Fatalf("test1: expected location %q, but got %q", expected, locations[i])
+			}
+		}
+	}
+	// test2: fatal exit cases
+	dir := t.TempDir()
+	for i, testcase := range []struct {
+		name    string
+		content string
+		message string
+	}{
+		{"a.txt", "", "FATAL: cannot load cases from %s: unknown extension \".txt\""},
+		{"a.json", "{}", "FATAL: cannot load cases from %s: line 1: expected array of cases"},
+		{"a.json", "[\n{},\n{\"x\": \"1\"}]", "FATAL: %s:3: cannot decode case #1: json: cannot unmarshal string into Go struct field addCase.x of type int"},
+		{"a.json", "[\n{},\n{]", "FATAL: cannot load cases from %s: line 3: invalid character ']' looking for beginning of object key string"},
+		{"a.csv", "x\n\"1", "FATAL: cannot load cases from %s: parse error on line 2, column 3: extraneous or missing \" in quoted-field"},
+		{"a.yaml", "a: 1", "FATAL: cannot load cases from %s: line 1: expected sequence of cases"},
+	} {
+		path := filepath.Join(dir, testcase.name)
+		if err := os.WriteFile(path, []byte(testcase.content), 0644); err != nil {
+			t.Fatalf("test2: unexpected error: %s", err)
+		}
+		tb2 := NewHookedTestingTB("test2")
+		func() {
+			defer func() {
+				recover()
+			}()
+			LoadCases[addCase](tb2, path)
+		}()
+		if !tb2.Failed() {
+			t.Fatalf("test2 #%d: unexpected Failed() == false", i)
+		}
+		if expected := strings.Replace(testcase.message, "%s", path, 1); !reflect.DeepEqual(tb2.Messages, []string{expected}) {
+			t.Fatalf("test2 #%d: unexpected Messages: %#v", i, tb2.Messages)
+		}
+	}
+	tb2 := NewHookedTestingTB("test2")
+	func() {
+		defer func() {
+			recover()
+		}()
+		LoadCases[addCase](tb2, filepath.Join(dir, "missing.json"))
+	}()
+	if !(len(tb2.Messages) == 1 && strings.HasPrefix(tb2.Messages[0], "FATAL: cannot load cases: open ")) {
+		t.Fatalf("test2: unexpected Messages: %#v", tb2.Messages)
+	}
+}
+
+func TestTableFile(t *testing.T) {
+	for _, path := range []string{"testdata/add.json", "testdata/add.yaml", "testdata/add.csv"} {
+		tb := NewHookedTestingTB("test")
+		TableFile(tb, path, func(a *Assert, tc addCase) {
+			a.New(tc.Sum).Equal(tc.X + tc.Y)
+		})
+		if !tb.Failed() {
+			t.Fatalf("test: unexpected Failed() == false for %s", path)
+		}
+		if len(tb.Subtests) != 3 || tb.Subtests[0].Failed() || tb.Subtests[2].Failed() {
+			t.Fatalf("test: unexpected Subtests for %s: %#v", path, tb.Subtests)
+		}
+		cases, locations := LoadCases[addCase](tb, path)
+		if expected := []string{
+			"ERROR: at #0 value, expected 4 (int), but got 3 (int)",
+			locations[1] + ": failed case #1: goassert.addCase{\n  Name: \"wrong\",\n  X: 1,\n  Y: 2,\n  Sum: 4,\n  Tags: []string(nil),\n}",
+		}; !(cases[1].Name == "wrong" && reflect.DeepEqual(tb.Subtests[1].Messages, expected)) {
+			t.Fatalf("test: unexpected Messages for %s: %#v", path, tb.Subtests[1].Messages)
+		}
+	}
+}
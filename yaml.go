@@ -0,0 +1,412 @@
+package goassert
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a significant line of the YAML document.
+type yamlLine struct {
+	// num is the 1-based line number.
+	num int
+	// indent is the number of the leading spaces.
+	indent int
+	// text is the content without the indentation and the comment.
+	text string
+	// raw is the content without the indentation, which is used in the block scalars.
+	raw string
+}
+
+// yamlParser is a parser of the subset of YAML used in the testdata files.
+// The supported subset consists of the block sequences and mappings, the plain and quoted scalars,
+// the flow sequences and mappings of scalars, and the literal (|) and folded (>) block scalars.
+// The anchors, aliases, tags, multi-line flow collections, multi-line plain scalars and multiple documents are not supported.
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// stripYAMLComment returns the text without the comment outside the quotes.
+func stripYAMLComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return strings.TrimRight(text[:i], " \t")
+		}
+	}
+	return strings.TrimRight(text, " \t")
+}
+
+// newYAMLParser returns a new yamlParser of the source.
+func newYAMLParser(src string) (*yamlParser, error) {
+	parser := &yamlParser{}
+	for i, line := range strings.Split(strings.Replace(src, "\r\n", "\n", -1), "\n") {
+		raw := strings.TrimLeft(line, " ")
+		indent := len(line) - len(raw)
+		if strings.HasPrefix(raw, "\t") {
+			return nil, fmt.Errorf("line %d: tabs cannot be used for indentation", i+1)
+		}
+		text := stripYAMLComment(raw)
+		if indent == 0 && (text == "---" || text == "...") {
+			continue
+		}
+		parser.lines = append(parser.lines, yamlLine{num: i + 1, indent: indent, text: text, raw: raw})
+	}
+	return parser, nil
+}
+
+// skipBlankLines skips the blank lines and the comment lines.
+func (parser *yamlParser) skipBlankLines() {
+	for parser.pos < len(parser.lines) && parser.lines[parser.pos].text == "" {
+		parser.pos++
+	}
+}
+
+// isYAMLSequenceItem returns true if the text is an item of the block sequence.
+func isYAMLSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitYAMLMapping splits the text into the key and the value of the block mapping.
+func splitYAMLMapping(text string) (string, string, bool) {
+	end := 0
+	if strings.HasPrefix(text, "\"") || strings.HasPrefix(text, "'") {
+		i := 1
+		for i < len(text) && text[i] != text[0] {
+			if text[i] == '\\' && text[0] == '"' {
+				i++
+			}
+			i++
+		}
+		if i >= len(text) {
+			return "", "", false
+		}
+		end = i + 1
+	}
+	i := strings.Index(text[end:], ":")
+	for i >= 0 && end+i+1 < len(text) && text[end+i+1] != ' ' {
+		next := strings.Index(text[end+i+1:], ":")
+		if next < 0 {
+			i = -1
+		} else {
+			i += next + 1
+		}
+	}
+	if i < 0 || strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		return "", "", false
+	}
+	key, err := parseYAMLScalar(strings.TrimSpace(text[:end+i]))
+	if err != nil {
+		return "", "", false
+	}
+	return fmt.Sprint(key), strings.TrimSpace(text[end+i+1:]), true
+}
+
+// splitYAMLFlow splits the content of the flow collection by the top-level commas.
+func splitYAMLFlow(text string) []string {
+	items := []string{}
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			items = append(items, strings.TrimSpace(text[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(text[start:]); last != "" || len(items) > 0 {
+		items = append(items, last)
+	}
+	return items
+}
+
+// parseYAMLScalar parses the scalar or the flow collection.
+// The numbers are returned as json.Number.
+func parseYAMLScalar(text string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(text, "\""):
+		return strconv.Unquote(text)
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return nil, fmt.Errorf("unterminated single-quoted scalar %s", text)
+		}
+		return strings.Replace(text[1:len(text)-1], "''", "'", -1), nil
+	case strings.HasPrefix(text, "["):
+		if !strings.HasSuffix(text, "]") {
+			return nil, fmt.Errorf("unterminated flow sequence %s", text)
+		}
+		seq := []interface{}{}
+		for _, item := range splitYAMLFlow(text[1 : len(text)-1]) {
+			v, err := parseYAMLScalar(item)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, v)
+		}
+		return seq, nil
+	case strings.HasPrefix(text, "{"):
+		if !strings.HasSuffix(text, "}") {
+			return nil, fmt.Errorf("unterminated flow mapping %s", text)
+		}
+		m := map[string]interface{}{}
+		for _, item := range splitYAMLFlow(text[1 : len(text)-1]) {
+			key, value, ok := splitYAMLMapping(item)
+			if !ok {
+				return nil, fmt.Errorf("malformed flow mapping entry %s", item)
+			}
+			v, err := parseYAMLScalar(value)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+		}
+		return m, nil
+	}
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return json.Number(strconv.FormatInt(i, 10)), nil
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil && !strings.ContainsAny(text, "xXpP_") && strings.ToLower(text) != "inf" && strings.ToLower(text) != "nan" {
+		return json.Number(strconv.FormatFloat(f, 'g', -1, 64)), nil
+	}
+	if strings.Contains(text, ": ") {
+		return nil, fmt.Errorf("mapping values are not allowed in plain scalar %s", text)
+	}
+	return text, nil
+}
+
+// isYAMLBlockScalar returns true if the value is the header of the literal or folded block scalar.
+func isYAMLBlockScalar(value string) bool {
+	return value == "|" || value == "|-" || value == ">" || value == ">-"
+}
+
+// foldYAMLLines returns the content of the folded block scalar.
+// The line break between the lines is folded into a space, and the break followed by the blank lines is folded into the newlines as many as the blank lines.
+// The line breaks around the more-indented lines are kept as they are.
+func foldYAMLLines(lines []string) string {
+	var text strings.Builder
+	started, prevMoreIndented, blanks := false, false, 0
+	for _, line := range lines {
+		if line == "" {
+			blanks++
+			continue
+		}
+		moreIndented := strings.HasPrefix(line, " ")
+		switch {
+		case !started:
+			text.WriteString(strings.Repeat("\n", blanks))
+		case moreIndented || prevMoreIndented:
+			text.WriteString(strings.Repeat("\n", blanks+1))
+		case blanks == 0:
+			text.WriteString(" ")
+		default:
+			text.WriteString(strings.Repeat("\n", blanks))
+		}
+		text.WriteString(line)
+		started, prevMoreIndented, blanks = true, moreIndented, 0
+	}
+	return text.String()
+}
+
+// parseBlockScalar parses the literal or folded block scalar following the current line.
+// The lines less indented than the first non-blank line of the block scalar are rejected.
+func (parser *yamlParser) parseBlockScalar(indent int, style string) (string, error) {
+	lines := []string{}
+	blockIndent := -1
+	for parser.pos < len(parser.lines) {
+		line := parser.lines[parser.pos]
+		if line.raw != "" && line.indent <= indent {
+			break
+		}
+		if blockIndent < 0 && line.raw != "" {
+			blockIndent = line.indent
+		}
+		if line.raw == "" {
+			lines = append(lines, "")
+		} else if line.indent < blockIndent {
+			return "", fmt.Errorf("line %d: bad indentation", line.num)
+		} else {
+			lines = append(lines, strings.Repeat(" ", line.indent-blockIndent)+line.raw)
+		}
+		parser.pos++
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	text := strings.Join(lines, "\n")
+	if strings.HasPrefix(style, ">") {
+		text = foldYAMLLines(lines)
+	}
+	if !strings.HasSuffix(style, "-") && len(lines) > 0 {
+		text += "\n"
+	}
+	return text, nil
+}
+
+// parseNode parses the node at the current line.
+func (parser *yamlParser) parseNode() (interface{}, error) {
+	line := parser.lines[parser.pos]
+	if isYAMLSequenceItem(line.text) {
+		return parser.parseSequence(line.indent, nil)
+	}
+	if _, _, ok := splitYAMLMapping(line.text); ok {
+		return parser.parseMapping(line.indent)
+	}
+	parser.pos++
+	v, err := parseYAMLScalar(line.text)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %s", line.num, err)
+	}
+	return v, nil
+}
+
+// parseChild parses the child node of the line at the indentation, or returns nil if no child exists.
+func (parser *yamlParser) parseChild(indent int, allowSequence bool) (interface{}, error) {
+	parser.skipBlankLines()
+	if parser.pos >= len(parser.lines) {
+		return nil, nil
+	}
+	if next := parser.lines[parser.pos]; next.indent > indent || (allowSequence && next.indent == indent && isYAMLSequenceItem(next.text)) {
+		return parser.parseNode()
+	}
+	return nil, nil
+}
+
+// parseSequence parses the block sequence at the indentation.
+// If itemLines is not nil, the line numbers of the items are appended to it.
+func (parser *yamlParser) parseSequence(indent int, itemLines *[]int) ([]interface{}, error) {
+	seq := []interface{}{}
+	for parser.skipBlankLines(); parser.pos < len(parser.lines); parser.skipBlankLines() {
+		line := parser.lines[parser.pos]
+		if line.indent < indent || (line.indent == indent && !isYAMLSequenceItem(line.text)) {
+			break
+		} else if line.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", line.num)
+		}
+		if itemLines != nil {
+			*itemLines = append(*itemLines, line.num)
+		}
+		rest := strings.TrimLeft(line.text[1:], " ")
+		var item interface{}
+		var err error
+		if rest == "" {
+			parser.pos++
+			item, err = parser.parseChild(indent, false)
+		} else if isYAMLBlockScalar(rest) {
+			parser.pos++
+			item, err = parser.parseBlockScalar(indent, rest)
+		} else {
+			// Parse the rest of the line as the node at the deeper indentation.
+			offset := len(line.text) - len(rest)
+			parser.lines[parser.pos] = yamlLine{num: line.num, indent: indent + offset, text: rest, raw: line.raw[offset:]}
+			item, err = parser.parseNode()
+		}
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, item)
+	}
+	return seq, nil
+}
+
+// parseMapping parses the block mapping at the indentation.
+func (parser *yamlParser) parseMapping(indent int) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	for parser.skipBlankLines(); parser.pos < len(parser.lines); parser.skipBlankLines() {
+		line := parser.lines[parser.pos]
+		if line.indent < indent {
+			break
+		} else if line.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", line.num)
+		}
+		key, value, ok := splitYAMLMapping(line.text)
+		if !ok {
+			if isYAMLSequenceItem(line.text) {
+				break
+			}
+			return nil, fmt.Errorf("line %d: expected mapping entry, but got %q", line.num, line.text)
+		}
+		if _, ok := m[key]; ok {
+			return nil, fmt.Errorf("line %d: duplicated key %q", line.num, key)
+		}
+		parser.pos++
+		var v interface{}
+		var err error
+		switch {
+		case value == "":
+			v, err = parser.parseChild(indent, true)
+		case isYAMLBlockScalar(value):
+			v, err = parser.parseBlockScalar(indent, value)
+		default:
+			if v, err = parseYAMLScalar(value); err != nil {
+				err = fmt.Errorf("line %d: %s", line.num, err)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
+
+// parseYAMLCases parses the YAML document which must be a block sequence of the cases.
+// parseYAMLCases returns the cases in JSON and their line numbers.
+func parseYAMLCases(src string) ([]json.RawMessage, []int, error) {
+	parser, err := newYAMLParser(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	parser.skipBlankLines()
+	if parser.pos >= len(parser.lines) {
+		return []json.RawMessage{}, []int{}, nil
+	}
+	if first := parser.lines[parser.pos]; first.indent != 0 || !isYAMLSequenceItem(first.text) {
+		return nil, nil, fmt.Errorf("line %d: expected sequence of cases", first.num)
+	}
+	lines := []int{}
+	seq, err := parser.parseSequence(0, &lines)
+	if err != nil {
+		return nil, nil, err
+	}
+	if parser.skipBlankLines(); parser.pos < len(parser.lines) {
+		return nil, nil, fmt.Errorf("line %d: unexpected content after sequence of cases", parser.lines[parser.pos].num)
+	}
+	cases := make([]json.RawMessage, len(seq))
+	for i, item := range seq {
+		if cases[i], err = json.Marshal(item); err != nil {
+			return nil, nil, fmt.Errorf("line %d: %s", lines[i], err)
+		}
+	}
+	return cases, lines, nil
+}
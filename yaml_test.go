@@ -0,0 +1,97 @@
+package goassert
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseYAMLCases(t *testing.T) {
+	// test1: only normal uses
+	cases, lines, err := parseYAMLCases(`# comment
+- name: scalars
+  int: -12
+  float: 1.5e3
+  bools: [true, False]
+  nulls: [~, null]
+  strings: ['it''s', "a\tb", plain text, "# not comment", http://example.com/]
+  flow: {a: 1, "b": [x, y]}
+-
+  name: nested
+  items:
+  - a: 1
+    b: 2
+  - - 1
+    - 2
+  object:
+    key: value # comment
+    "quoted key": 'quoted value'
+- name: blocks
+  literal: |
+    line1
+      line2 # not comment
+
+  folded: >-
+    word1
+    word2
+  paragraphs: >
+    para1
+    line
+
+    para2
+      more indented
+
+    last
+- plain
+- []
+- |
+  raw text
+`)
+	if err != nil {
+		t.Fatalf("test1: unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(lines, []int{2, 9, 19, 35, 36, 37}) {
+		t.Fatalf("test1: unexpected lines: %#v", lines)
+	}
+	got := make([]interface{}, len(cases))
+	for i, raw := range cases {
+		if err := json.Unmarshal(raw, &got[i]); err != nil {
+			t.Fatalf("test1: unexpected error: %s", err)
+		}
+	}
+	var expected []interface{}
+	if err := json.Unmarshal([]byte(`[
+		{"name": "scalars", "int": -12, "float": 1500, "bools": [true, false], "nulls": [null, null],
+		 "strings": ["it's", "a\tb", "plain text", "# not comment", "http://example.com/"], "flow": {"a": 1, "b": ["x", "y"]}},
+		{"name": "nested", "items": [{"a": 1, "b": 2}, [1, 2]], "object": {"key": "value", "quoted key": "quoted value"}},
+		{"name": "blocks", "literal": "line1\n  line2 # not comment\n", "folded": "word1 word2",
+		 "paragraphs": "para1 line\npara2\n  more indented\n\nlast\n"},
+		"plain",
+		[],
+		"raw text\n"
+	]`), &expected); err != nil {
+		t.Fatalf("test1: unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("test1: expected %#v, but got %#v", expected, got)
+	}
+	// test2: error cases
+	for _, testcase := range []struct {
+		src     string
+		message string
+	}{
+		{"a: 1", "line 1: expected sequence of cases"},
+		{"- a: 1\n    b: 2", "line 2: unexpected indentation"},
+		{"- a: 1\n  a: 2", "line 2: duplicated key \"a\""},
+		{"- a: 1\n  b", "line 2: expected mapping entry, but got \"b\""},
+		{"- a: [1", "line 1: unterminated flow sequence [1"},
+		{"- a\nb: 1", "line 2: unexpected content after sequence of cases"},
+		{"\t- a", "line 1: tabs cannot be used for indentation"},
+		{"- text: |\n      a\n    b\n", "line 3: bad indentation"},
+		{"- a: b: c", "line 1: mapping values are not allowed in plain scalar b: c"},
+	} {
+		if _, _, err := parseYAMLCases(testcase.src); err == nil || err.Error() != testcase.message {
+			t.Fatalf("test2: unexpected error for %q: %v", testcase.src, err)
+		}
+	}
+}